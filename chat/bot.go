@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
@@ -71,6 +72,16 @@ func handleBotCommands(user *sessions.User, args []string) string {
 		return handleBotCommandMuteUser(user, args)
 	case "unmute":
 		return handleBotCommandUnmuteUser(user, args)
+	case "sessioninfo":
+		return handleBotCommandSessionInfo(user, args)
+	case "metrics":
+		return handleBotCommandMetrics(user)
+	case "reloadfilter":
+		return handleBotCommandReloadFilter(user)
+	case "invisible":
+		return handleBotCommandInvisible(user)
+	case "refreshstatuses":
+		return handleBotCommandRefreshStatuses(user)
 	default:
 		return ""
 	}
@@ -98,6 +109,8 @@ func handleBotCommandKick(user *sessions.User, args []string) string {
 
 	sessions.SendPacketToUser(packets.NewServerNotificationError("You have been kicked from the server."), target)
 	utils.CloseConnectionDelayed(target.Conn)
+	log.Printf("[%v #%v] Kicked %v #%v (%v - %v)\n", user.Info.Username, user.Info.Id, target.Info.Username, target.Info.Id,
+		target.GetIpAddress(), target.GetUserAgent())
 	return fmt.Sprintf("%v has been kicked from the server.", target.Info.Username)
 }
 
@@ -212,10 +225,16 @@ func handleBotCommandMuteUser(user *sessions.User, args []string) string {
 	// but if they're offline, we can just skip to updating it in the DB.
 	onlineUser := getUserFromCommandArgs(args)
 
+	reason := ""
+
+	if len(args) > 4 {
+		reason = strings.Join(args[4:], " ")
+	}
+
 	if onlineUser != nil {
-		err = onlineUser.MuteUser(duration)
+		err = onlineUser.MuteUser(duration, reason, user.Info.Id)
 	} else {
-		err = sessions.NewUser(nil, target).MuteUser(duration)
+		err = sessions.NewUser(nil, target).MuteUser(duration, reason, user.Info.Id)
 	}
 
 	if err != nil {
@@ -240,6 +259,105 @@ func handleBotCommandUnmuteUser(user *sessions.User, args []string) string {
 	return handleBotCommandMuteUser(user, []string{"unmute", args[1], "0", "s"})
 }
 
+// Handles the command to inspect the connection info of an online user
+func handleBotCommandSessionInfo(user *sessions.User, args []string) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeKickUsers) {
+		return ""
+	}
+
+	if len(args) < 2 {
+		return "You must specify a user to inspect."
+	}
+
+	target := getUserFromCommandArgs(args)
+
+	if target == nil {
+		return "That user is not online."
+	}
+
+	ip := target.GetIpAddress()
+
+	// Only users that can view admin logs are able to see the full address.
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeViewAdminLogs) {
+		ip = utils.RedactIpAddress(ip)
+	}
+
+	return fmt.Sprintf("%v (#%v) - IP: %v - User Agent: %v", target.Info.Username, target.Info.Id, ip, target.GetUserAgent())
+}
+
+// Handles the command to toggle appearing offline to other users
+func handleBotCommandInvisible(user *sessions.User) string {
+	if !isChatModerator(user.Info.UserGroups) {
+		return ""
+	}
+
+	invisible := !user.IsInvisible()
+
+	if err := sessions.SetUserInvisible(user, invisible); err != nil {
+		log.Printf("[%v #%v] Failed to update invisibility - %v\n", user.Info.Username, user.Info.Id, err)
+	}
+
+	if invisible {
+		return "You are now invisible and appear offline to other users."
+	}
+
+	return "You are now visible to other users."
+}
+
+// Handles the command to view the collected server metrics
+func handleBotCommandMetrics(user *sessions.User) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeViewAdminLogs) {
+		return ""
+	}
+
+	counters := metrics.GetCounters()
+
+	if len(counters) == 0 {
+		return "No metrics have been collected yet."
+	}
+
+	lines := []string{"Server Metrics:"}
+
+	for _, name := range metrics.GetCounterNames() {
+		lines = append(lines, fmt.Sprintf("- %v: %v", name, counters[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Handles the command to reload the chat filter rules
+func handleBotCommandReloadFilter(user *sessions.User) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeMuteUsers) {
+		return ""
+	}
+
+	count, err := ReloadFilter()
+
+	if err != nil {
+		log.Printf("Error reloading chat filter - %v\n", err)
+		return "An error occurred while reloading the chat filter."
+	}
+
+	return fmt.Sprintf("The chat filter has been reloaded with %v rules.", count)
+}
+
+// Handles the command to rewrite every online user's client status to redis
+func handleBotCommandRefreshStatuses(user *sessions.User) string {
+	if !common.HasPrivilege(user.Info.Privileges, common.PrivilegeEditUsers) {
+		return ""
+	}
+
+	count, err := sessions.RefreshAllClientStatuses()
+
+	if err != nil {
+		log.Printf("Error refreshing client statuses - %v\n", err)
+		return fmt.Sprintf("An error occurred after refreshing %v client statuses.", count)
+	}
+
+	log.Printf("[%v #%v] Refreshed %v client statuses in redis\n", user.Info.Username, user.Info.Id, count)
+	return fmt.Sprintf("Refreshed the client statuses of %v online users.", count)
+}
+
 // getUserFromCommandArgs Returns a target user from command args
 func getUserFromCommandArgs(args []string) *sessions.User {
 	return sessions.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[1], "_", " ")))
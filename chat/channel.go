@@ -19,7 +19,8 @@ type Channel struct {
 	LimitedChat    bool
 	DiscordWebhook string
 	WebhookClient  webhook.Client
-	Participants   map[int]*sessions.User
+	Participants   map[*sessions.User]bool        // Keyed by session, so users on multiple devices receive messages on each of them
+	CanSend        func(user *sessions.User) bool // Optional check for if a user is allowed to send messages to the channel
 	mutex          *sync.Mutex
 }
 
@@ -42,7 +43,7 @@ func NewChannel(channelType ChannelType, name string, description string, adminO
 		LimitedChat:    limitedChat,
 		DiscordWebhook: discordWebhook,
 		WebhookClient:  nil,
-		Participants:   map[int]*sessions.User{},
+		Participants:   map[*sessions.User]bool{},
 		mutex:          &sync.Mutex{},
 	}
 
@@ -82,7 +83,7 @@ func (channel *Channel) AddUser(user *sessions.User) {
 		return
 	}
 
-	channel.Participants[user.Info.Id] = user
+	channel.Participants[user] = true
 	sessions.SendPacketToUser(packets.NewServerJoinedChatChannel(channel.Name), user)
 }
 
@@ -95,9 +96,7 @@ func (channel *Channel) RemoveUser(user *sessions.User) {
 		return
 	}
 
-	if _, ok := channel.Participants[user.Info.Id]; ok {
-		delete(channel.Participants, user.Info.Id)
-	}
+	delete(channel.Participants, user)
 
 	sessions.SendPacketToUser(packets.NewServerLeftChatChannel(channel.Name), user)
 }
@@ -109,8 +108,8 @@ func (channel *Channel) SendMessage(sender *sessions.User, message string) {
 
 	packet := packets.NewServerChatMessage(sender.Info.Id, sender.Info.Username, channel.Name, message)
 
-	for _, user := range channel.Participants {
-		if user == sender {
+	for user := range channel.Participants {
+		if user == sender || user.IsBlocking(sender.Info.Id) {
 			continue
 		}
 
@@ -132,7 +131,12 @@ func (channel *Channel) sendWebhook(sender *sessions.User, message string) {
 // Removes all users from the channel
 func (channel *Channel) removeAllUsers() {
 	channel.mutex.Lock()
-	participants := channel.Participants
+	participants := make([]*sessions.User, 0, len(channel.Participants))
+
+	for user := range channel.Participants {
+		participants = append(participants, user)
+	}
+
 	channel.mutex.Unlock()
 
 	for _, user := range participants {
@@ -145,6 +149,5 @@ func (channel *Channel) isUserInChannel(user *sessions.User) bool {
 	channel.mutex.Lock()
 	defer channel.mutex.Unlock()
 
-	_, ok := channel.Participants[user.Info.Id]
-	return ok
+	return channel.Participants[user]
 }
@@ -1,6 +1,9 @@
 package chat
 
 import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/sessions"
+	"sync"
 	"testing"
 )
 
@@ -18,3 +21,34 @@ func TestChannelSendWebhook(t *testing.T) {
 
 	channel.sendWebhook(user, "This is a test")*/
 }
+
+func TestChannelKeepsOtherSessionsAfterDisconnect(t *testing.T) {
+	channels = map[string]*Channel{}
+	chatMutex = &sync.Mutex{}
+
+	channel := NewChannel(ChannelNormal, "#sessions", "", false, false, false, "")
+	addChannel(channel)
+	defer removeChannel(channel)
+
+	info := &db.User{Id: 1000, SteamId: "1000", Username: "Multi Device"}
+	desktop := sessions.NewUser(nil, info)
+	mobile := sessions.NewUser(nil, info)
+
+	channel.AddUser(desktop)
+	channel.AddUser(mobile)
+
+	if !channel.isUserInChannel(desktop) || !channel.isUserInChannel(mobile) {
+		t.Fatal("Expected both sessions to be in the channel")
+	}
+
+	// This is what runs when a session disconnects
+	RemoveUserFromAllChannels(desktop)
+
+	if channel.isUserInChannel(desktop) {
+		t.Fatal("Expected the disconnected session to leave the channel")
+	}
+
+	if !channel.isUserInChannel(mobile) {
+		t.Fatal("Expected the other session to stay in the channel")
+	}
+}
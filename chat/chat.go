@@ -33,9 +33,14 @@ func Initialize() {
 		addChannel(NewChannel(ChannelNormal, channel.Name, channel.Description, channel.AdminOnly, channel.AutoJoin, channel.LimitedChat, channel.DiscordWebhook))
 	}
 
+	if _, err := ReloadFilter(); err != nil {
+		log.Printf("Failed to load chat filter - %v\n", err)
+	}
+
 	_ = sessions.AddUser(Bot)
 	addBotChatHandlers()
 	addSpectatorHandlers()
+	sessions.AddDisconnectHandler(RemoveUserFromAllChannels)
 }
 
 // GetAvailableChannels Returns the available channels that the user is able to join
@@ -76,10 +81,17 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 		return
 	}
 
+	message, rejected := FilterMessage(message)
+
+	if rejected {
+		sessions.SendPacketToUser(packets.NewServerNotificationError("Your message was not sent because it contains a blocked word."), sender)
+		return
+	}
+
 	sender.IncrementSpammedMessagesCount()
 
 	if sender.GetSpammedMessagesCount() >= 10 && !isChatModerator(sender.Info.UserGroups) {
-		_ = sender.MuteUser(time.Minute * 30)
+		_ = sender.MuteUser(time.Minute*30, "Spamming", Bot.Info.Id)
 		return
 	}
 
@@ -100,13 +112,18 @@ func SendMessage(sender *sessions.User, receiver string, message string) {
 			return
 		}
 
+		if channel.CanSend != nil && !channel.CanSend(sender) {
+			return
+		}
+
 		channel.SendMessage(sender, message)
 		webhooks.SendChatMessage(channel.WebhookClient, sender.Info.Username, sender.Info.GetProfileUrl(), sender.Info.AvatarUrl.String, receiver, message)
 		runPublicMessageHandlers(sender, channel, message)
 	} else {
 		receivingUser := sessions.GetUserByUsername(receiver)
 
-		if receivingUser == nil {
+		// Messages to users who have blocked the sender are dropped without telling them
+		if receivingUser == nil || receivingUser.IsBlocking(sender.Info.Id) {
 			return
 		}
 
@@ -178,9 +195,19 @@ func AddPrivateMessageHandler(f func(user *sessions.User, receivingUser *session
 	privateMessageHandlers = append(privateMessageHandlers, f)
 }
 
-// Sends a private message to a user
+// Sends a private message to a user on every device they're signed in on
 func sendPrivateMessage(sender *sessions.User, receiver *sessions.User, message string) {
-	sessions.SendPacketToUser(packets.NewServerChatMessage(sender.Info.Id, sender.Info.Username, receiver.Info.Username, message), receiver)
+	packet := packets.NewServerChatMessage(sender.Info.Id, sender.Info.Username, receiver.Info.Username, message)
+	receivers := sessions.GetUserSessions(receiver.Info.Id)
+
+	// The receiver's session may have already been removed, such as when the bot replies to a user logging out
+	if len(receivers) == 0 {
+		receivers = []*sessions.User{receiver}
+	}
+
+	for _, session := range receivers {
+		sessions.SendPacketToUser(packet, session)
+	}
 
 	err := db.InsertPrivateChatMessage(sender.Info.Id, receiver.Info.Id, receiver.Info.Username, message)
 
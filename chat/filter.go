@@ -0,0 +1,114 @@
+package chat
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/config"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type FilterSeverity string
+
+const (
+	FilterSeverityCensor FilterSeverity = "censor"
+	FilterSeverityReject FilterSeverity = "reject"
+)
+
+// FilterRule A single word or regex rule read from the chat filter file
+type FilterRule struct {
+	Pattern  string         `json:"pattern"`
+	Regex    bool           `json:"regex"`
+	Severity FilterSeverity `json:"severity"`
+}
+
+type compiledFilterRule struct {
+	expression *regexp.Regexp
+	severity   FilterSeverity
+}
+
+var (
+	filterRules []*compiledFilterRule
+	filterMutex = &sync.RWMutex{}
+)
+
+// ReloadFilter Reads the chat filter file from config and replaces the active rules.
+// The existing rules are kept if the file cannot be read or contains an invalid rule.
+func ReloadFilter() (int, error) {
+	if config.Instance == nil || config.Instance.ChatFilterPath == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(config.Instance.ChatFilterPath)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var rules []FilterRule
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return 0, err
+	}
+
+	compiled := make([]*compiledFilterRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			continue
+		}
+
+		switch rule.Severity {
+		case FilterSeverityCensor, FilterSeverityReject:
+		default:
+			return 0, fmt.Errorf("invalid severity `%v` for filter rule `%v`", rule.Severity, rule.Pattern)
+		}
+
+		pattern := rule.Pattern
+
+		if !rule.Regex {
+			pattern = `\b` + regexp.QuoteMeta(pattern) + `\b`
+		}
+
+		expression, err := regexp.Compile("(?i)" + pattern)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid filter rule `%v` - %v", rule.Pattern, err)
+		}
+
+		compiled = append(compiled, &compiledFilterRule{expression: expression, severity: rule.Severity})
+	}
+
+	filterMutex.Lock()
+	filterRules = compiled
+	filterMutex.Unlock()
+
+	log.Printf("Loaded %v chat filter rules\n", len(compiled))
+	return len(compiled), nil
+}
+
+// FilterMessage Runs a message through the chat filter.
+// Returns the (possibly censored) message and whether the message should be rejected.
+func FilterMessage(message string) (string, bool) {
+	filterMutex.RLock()
+	defer filterMutex.RUnlock()
+
+	for _, rule := range filterRules {
+		if !rule.expression.MatchString(message) {
+			continue
+		}
+
+		if rule.severity == FilterSeverityReject {
+			return message, true
+		}
+
+		message = rule.expression.ReplaceAllStringFunc(message, func(match string) string {
+			return strings.Repeat("*", len([]rune(match)))
+		})
+	}
+
+	return message, false
+}
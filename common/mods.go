@@ -1,5 +1,10 @@
 package common
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Mods int64
 
 const (
@@ -96,6 +101,63 @@ func GetSpeedModFromMods(mods Mods) Mods {
 	return 0
 }
 
+// ValidateModifiers Returns an error if a group of modifiers cannot be used together in multiplayer
+func ValidateModifiers(mods Mods) error {
+	if mods < 0 || mods >= ModEnumMaxValue {
+		return fmt.Errorf("%v contains unknown modifiers", int64(mods))
+	}
+
+	speedMods := 0
+
+	for _, speedMod := range SpeedMods {
+		if mods&speedMod != 0 {
+			speedMods++
+		}
+	}
+
+	if speedMods > 1 {
+		return fmt.Errorf("only one speed modifier can be active at a time")
+	}
+
+	if mods&(ModAutoplay|ModPaused|ModCoop) != 0 {
+		return fmt.Errorf("autoplay, paused and co-op modifiers are not allowed")
+	}
+
+	if mods&ModNoLongNotes != 0 && mods&(ModFullLN|ModInverse) != 0 {
+		return fmt.Errorf("no long notes cannot be combined with full long notes or inverse")
+	}
+
+	return nil
+}
+
+// ParseModString Parses a comma separated list of modifier strings (ex. `NF,1.1x`) and validates them
+func ParseModString(str string) (Mods, error) {
+	modMap := GetModStrings()
+	var mods Mods
+
+	for _, name := range strings.Split(str, ",") {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		mod, ok := modMap[name]
+
+		if !ok {
+			return 0, fmt.Errorf("`%v` is not a valid modifier", name)
+		}
+
+		mods |= mod
+	}
+
+	if err := ValidateModifiers(mods); err != nil {
+		return 0, err
+	}
+
+	return mods, nil
+}
+
 // GetModStrings Returns the active modifiers from string
 func GetModStrings() map[string]Mods {
 	modMap := map[string]Mods{
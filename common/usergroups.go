@@ -14,6 +14,35 @@ const (
 	UserGroupDonator
 )
 
+// The readable names of each user group, in bit order
+var userGroupNames = []struct {
+	group UserGroups
+	name  string
+}{
+	{UserGroupNormal, "Normal"},
+	{UserGroupAdmin, "Admin"},
+	{UserGroupBot, "Bot"},
+	{UserGroupDeveloper, "Developer"},
+	{UserGroupModerator, "Moderator"},
+	{UserGroupRankingSupervisor, "RankingSupervisor"},
+	{UserGroupSwan, "Swan"},
+	{UserGroupContributor, "Contributor"},
+	{UserGroupDonator, "Donator"},
+}
+
+// GetUserGroupNames Returns the readable names of every group in a combination of user groups
+func GetUserGroupNames(groupsCombo UserGroups) []string {
+	names := make([]string, 0)
+
+	for _, group := range userGroupNames {
+		if HasUserGroup(groupsCombo, group.group) {
+			names = append(names, group.name)
+		}
+	}
+
+	return names
+}
+
 // HasUserGroup Returns if a combination of user groups contains a single group
 func HasUserGroup(groupsCombo UserGroups, group UserGroups) bool {
 	return groupsCombo&group != 0
@@ -34,3 +63,33 @@ func HasAnyUserGroup(groupsCombo UserGroups, groups []UserGroups) bool {
 func IsSwan(userGroups UserGroups) bool {
 	return HasUserGroup(userGroups, UserGroupSwan)
 }
+
+// Has Returns if the combination contains every group in a group or mask of groups
+func (g UserGroups) Has(group UserGroups) bool {
+	return group != 0 && g&group == group
+}
+
+// HasAny Returns if the combination contains at least one of the groups in a mask of groups
+func (g UserGroups) HasAny(groups UserGroups) bool {
+	return g&groups != 0
+}
+
+// IsAdmin Returns if the combination contains the admin group
+func (g UserGroups) IsAdmin() bool {
+	return g.Has(UserGroupAdmin)
+}
+
+// IsModerator Returns if the combination contains the moderator group
+func (g UserGroups) IsModerator() bool {
+	return g.Has(UserGroupModerator)
+}
+
+// IsDeveloper Returns if the combination contains the developer group
+func (g UserGroups) IsDeveloper() bool {
+	return g.Has(UserGroupDeveloper)
+}
+
+// IsBot Returns if the combination contains the bot group
+func (g UserGroups) IsBot() bool {
+	return g.Has(UserGroupBot)
+}
@@ -0,0 +1,31 @@
+package common
+
+import "testing"
+
+func TestUserGroupsPredicates(t *testing.T) {
+	groups := UserGroups(UserGroupNormal | UserGroupAdmin | UserGroupDeveloper)
+
+	if !groups.IsAdmin() || !groups.IsDeveloper() {
+		t.Fatal("Expected the groups to be admin and developer")
+	}
+
+	if groups.IsModerator() || groups.IsBot() {
+		t.Fatal("Expected the groups to not be moderator or bot")
+	}
+
+	if !groups.Has(UserGroupAdmin | UserGroupDeveloper) {
+		t.Fatal("Expected the groups to have every group in the mask")
+	}
+
+	if groups.Has(UserGroupAdmin | UserGroupModerator) {
+		t.Fatal("Expected the groups to not have a mask that is only partially present")
+	}
+
+	if !groups.HasAny(UserGroupAdmin | UserGroupModerator) {
+		t.Fatal("Expected the groups to have any of a partially present mask")
+	}
+
+	if groups.Has(0) || groups.HasAny(0) {
+		t.Fatal("Expected an empty mask to never match")
+	}
+}
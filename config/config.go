@@ -10,6 +10,65 @@ import (
 type Configuration struct {
 	Server struct {
 		Port int `json:"port"`
+
+		// The amount of seconds a new session has before it is considered by the ping timeout monitor
+		PongGracePeriod int `json:"pong_grace_period"`
+
+		// The amount of seconds a ping can go unanswered before the user is disconnected. Defaults to 120.
+		PingTimeout int `json:"ping_timeout"`
+
+		// The amount of seconds between pings sent to each online user. Defaults to 40.
+		PingInterval int `json:"ping_interval"`
+
+		// The maximum amount of simultaneous connections from a single ip address. Unlimited if zero.
+		MaxConnectionsPerIp int `json:"max_connections_per_ip"`
+
+		// Ip addresses (ex. shared NATs) that are not affected by the connection limit
+		ConnectionLimitAllowlist []string `json:"connection_limit_allowlist"`
+
+		// The ip addresses or CIDR ranges of reverse proxies in front of the server. X-Forwarded-For is ignored
+		// unless the connection comes from one of them, as clients are otherwise able to set it to anything.
+		TrustedProxies []string `json:"trusted_proxies"`
+
+		// The amount of goroutines packet broadcasts are fanned out across. Set to 1 to send sequentially.
+		BroadcastWorkers int `json:"broadcast_workers"`
+
+		// The amount of packets per second a client can send before they are dropped. Disabled if zero.
+		PacketRateLimit int `json:"packet_rate_limit"`
+
+		// The amount of packets a client can send in a burst before the rate limit kicks in
+		PacketRateLimitBurst int `json:"packet_rate_limit_burst"`
+
+		// If serialized users also include the readable names of their user groups
+		SerializeUserGroupNames bool `json:"serialize_user_group_names"`
+
+		// The maximum size of an outbound packet in bytes. Disabled if zero.
+		MaxPacketSize int `json:"max_packet_size"`
+
+		// What happens to packets over the maximum size. Either "reject" (default) or "chunk".
+		OversizedPacketPolicy string `json:"oversized_packet_policy"`
+
+		// The amount of packets that can be waiting to be written to a connection
+		OutboundQueueSize int `json:"outbound_queue_size"`
+
+		// What happens when a connection's outbound queue is full. Either "drop" (default) or "disconnect".
+		OutboundQueuePolicy string `json:"outbound_queue_policy"`
+
+		// Packets of at least this many bytes are compressed for clients that support it. Disabled if zero.
+		CompressionThreshold int `json:"compression_threshold"`
+
+		// The maximum size in bytes that a compressed message from a client can inflate to. Defaults to 1 MiB.
+		// Clients that go over it are disconnected.
+		MaxInflatedMessageSize int `json:"max_inflated_message_size"`
+
+		// The amount of seconds a session token lives in redis without being refreshed by a pong. Defaults to a day.
+		SessionTokenTtl int `json:"session_token_ttl"`
+
+		// The amount of sessions a user can have at once across devices. Defaults to 1.
+		MaxSessionsPerUser int `json:"max_sessions_per_user"`
+
+		// The amount of seconds without any packets before a user is shown as away. Defaults to 10 minutes.
+		IdleTimeout int `json:"idle_timeout"`
 	} `json:"server"`
 
 	BypassSteamLogin bool `json:"bypass_steam_login"`
@@ -25,6 +84,9 @@ type Configuration struct {
 		Host     string `json:"host"`
 		Password string `json:"password"`
 		Database int    `json:"database"`
+
+		// The prefix of every key the server stores in redis. Defaults to "quaver:server:".
+		KeyPrefix string `json:"key_prefix"`
 	}
 
 	Steam struct {
@@ -48,6 +110,76 @@ type Configuration struct {
 		DiscordWebhook string `json:"discord_webhook"`
 		LimitedChat    bool   `json:"limited_chat"`
 	} `json:"chat_channels"`
+
+	ChatFilterPath string `json:"chat_filter_path"`
+
+	Multiplayer struct {
+		// The maximum amount of outstanding invites a user can have sent within the invite window
+		MaxOutstandingInvites int `json:"max_outstanding_invites"`
+
+		// The amount of seconds before a sent invite expires and no longer counts towards the limit
+		InviteWindow int `json:"invite_window"`
+
+		// The global modifiers new games start with, keyed by game mode (keys4/keys7). Ex. `NF,1.1x`
+		DefaultModifiers map[string]string `json:"default_modifiers"`
+
+		// The chat channel that newly created public games are announced in. Disabled if empty.
+		AnnounceChannel string `json:"announce_channel"`
+
+		// The amount of seconds a referee's role is held for after they leave, so they can reconnect
+		RefereeGracePeriod int `json:"referee_grace_period"`
+
+		// What happens once the referee grace period runs out. Either "release" (default) or "end" to also end the match.
+		RefereeTimeoutAction string `json:"referee_timeout_action"`
+
+		// If the match in progress is paused while a disconnected referee's role is held.
+		// It's resumed once they reconnect or their grace period runs out.
+		RefereeDisconnectPause bool `json:"referee_disconnect_pause"`
+
+		// The maximum amount of spectators across every game on the server. Unlimited if zero.
+		MaxTotalSpectators int `json:"max_total_spectators"`
+
+		// The minimum amount of milliseconds between live scoreboard updates
+		ScoreboardInterval int `json:"scoreboard_interval"`
+
+		// If live scoreboards should also be published to redis for casting tools
+		PublishScoreboard bool `json:"publish_scoreboard"`
+
+		// Keeps cached games in redis on startup instead of clearing them, and only removes orphaned player keys
+		PreserveRedisGames bool `json:"preserve_redis_games"`
+
+		// Appends the user id to players with matching names in a game's serialized player list
+		DisambiguateUsernames bool `json:"disambiguate_usernames"`
+
+		// The amount of seconds cached games live in redis without being refreshed, so games left behind by a crash
+		// expire on their own. Defaults to an hour.
+		CachedGameTtl int `json:"cached_game_ttl"`
+
+		// The amount of seconds players have to ready up once the host starts the match. Disabled if zero.
+		ReadyCheckTimeout int `json:"ready_check_timeout"`
+
+		// What happens to players who don't ready up in time. Either "exclude" (default) to start without them,
+		// or "cancel" to cancel the start.
+		ReadyCheckAction string `json:"ready_check_action"`
+	} `json:"multiplayer"`
+
+	// Stops users from being notified when their mute expires
+	DisableMuteExpiryNotifications bool `json:"disable_mute_expiry_notifications"`
+
+	// The amount of milliseconds a user has to wait between switching spectator targets
+	SpectateSwitchCooldown int `json:"spectate_switch_cooldown"`
+
+	// The maximum amount of spectator replay frame packets buffered across every user
+	MaxBufferedReplayFrames int `json:"max_buffered_replay_frames"`
+
+	// The amount of seconds between automatic stats refreshes for online users. Disabled if zero.
+	StatsRefreshInterval int `json:"stats_refresh_interval"`
+
+	// The amount of seconds between each flush of the presence counts to redis. Disabled if zero.
+	PresenceFlushInterval int `json:"presence_flush_interval"`
+
+	// The directory that server state dumps are written to
+	DiagnosticsDirectory string `json:"diagnostics_directory"`
 }
 
 var Instance *Configuration
@@ -16,6 +16,12 @@ var (
 	RedisChannelTwitchConnection     = "quaver:twitch_connection"
 	RedisChannelMultiplayerMapShares = "quaver:multiplayer_map_shares"
 	RedisChannelFirstPlaceScores     = "quaver:first_place_scores"
+
+	// Published to only, for casting overlays
+	RedisChannelMultiplayerScoreboard = "quaver:multiplayer_scoreboard"
+
+	// Published to only, for dashboards and the website
+	RedisChannelOnlineUserUpdates = "quaver:server:online_users:updates"
 )
 
 // InitializeRedis Initializes a Redis client
@@ -24,6 +30,8 @@ func InitializeRedis() {
 		return
 	}
 
+	loadKeyPrefix()
+
 	Redis = redis.NewClient(&redis.Options{
 		Addr:         config.Instance.Redis.Host,
 		Password:     config.Instance.Redis.Password,
@@ -32,6 +40,8 @@ func InitializeRedis() {
 		WriteTimeout: time.Minute,
 	})
 
+	Redis.AddHook(redisBreaker)
+
 	result := Redis.Ping(RedisCtx)
 	log.Println("Successfully connected to redis")
 
@@ -68,19 +78,53 @@ func AddRedisSubscriberHandler(channel string, f func(message *redis.Message)) {
 	redisChannelHandlers[channel] = append(redisChannelHandlers[channel], f)
 }
 
-// ClearRedisKeysWithPattern Clears a given pattern of redis keys from the database
+// The amount of keys requested per SCAN call, and deleted per DEL command when clearing keys
+const redisScanBatchSize = 1000
+
+// ClearRedisKeysWithPattern Clears a given pattern of redis keys from the database.
+// Keys are found with SCAN in batches rather than KEYS, so redis isn't blocked on large keyspaces,
+// and each batch is deleted in a single pipeline.
 func ClearRedisKeysWithPattern(pattern string) error {
-	keys, err := Redis.Keys(RedisCtx, pattern).Result()
+	var cursor uint64
 
-	if err != nil {
-		return err
+	for {
+		keys, next, err := Redis.Scan(RedisCtx, cursor, pattern, redisScanBatchSize).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if err := deleteRedisKeys(keys); err != nil {
+			return err
+		}
+
+		cursor = next
+
+		if cursor == 0 {
+			return nil
+		}
 	}
+}
 
+// Deletes keys in pipelined chunks
+func deleteRedisKeys(keys []string) error {
 	if len(keys) == 0 {
 		return nil
 	}
 
-	_, err = Redis.Del(RedisCtx, keys...).Result()
+	pipe := Redis.Pipeline()
+
+	for start := 0; start < len(keys); start += redisScanBatchSize {
+		end := start + redisScanBatchSize
+
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		pipe.Del(RedisCtx, keys[start:end]...)
+	}
+
+	_, err := pipe.Exec(RedisCtx)
 
 	if err != nil {
 		return err
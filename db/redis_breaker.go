@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrRedisUnavailable Returned for redis commands while the circuit breaker is open
+var ErrRedisUnavailable = errors.New("redis is currently unavailable")
+
+const (
+	redisBreakerFailureThreshold = 5               // Consecutive failures before switching to local-only mode
+	redisBreakerProbeInterval    = 5 * time.Second // How often redis is pinged while in local-only mode
+)
+
+type redisProbeContextKey struct{}
+
+// Detects sustained redis failures and short-circuits commands until redis is reachable again
+type redisCircuitBreaker struct {
+	mutex               *sync.Mutex
+	consecutiveFailures int
+	open                bool
+}
+
+var redisBreaker = &redisCircuitBreaker{mutex: &sync.Mutex{}}
+
+// IsRedisAvailable Returns if redis is reachable. When false, the server runs in local-only mode
+// and non-essential caching should be skipped.
+func IsRedisAvailable() bool {
+	redisBreaker.mutex.Lock()
+	defer redisBreaker.mutex.Unlock()
+
+	return !redisBreaker.open
+}
+
+// Records the result of a redis command and opens the breaker after too many consecutive failures
+func (b *redisCircuitBreaker) recordResult(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !isRedisConnectionError(err) {
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+
+	if b.open || b.consecutiveFailures < redisBreakerFailureThreshold {
+		return
+	}
+
+	b.open = true
+	log.Printf("Redis has failed %v times in a row (%v). Switching to local-only mode.\n", b.consecutiveFailures, err)
+	go b.probe()
+}
+
+// Pings redis until it is reachable again, then closes the breaker
+func (b *redisCircuitBreaker) probe() {
+	ctx := context.WithValue(RedisCtx, redisProbeContextKey{}, true)
+
+	for {
+		time.Sleep(redisBreakerProbeInterval)
+
+		if err := Redis.Ping(ctx).Err(); err != nil {
+			continue
+		}
+
+		b.mutex.Lock()
+		b.open = false
+		b.consecutiveFailures = 0
+		b.mutex.Unlock()
+
+		log.Println("Redis is reachable again. Leaving local-only mode.")
+		return
+	}
+}
+
+// Returns if an error means redis couldn't be reached, rather than a regular reply such as a missing key
+func isRedisConnectionError(err error) bool {
+	if err == nil || err == redis.Nil || err == ErrRedisUnavailable {
+		return false
+	}
+
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
+
+// BeforeProcess Fails fast while the breaker is open, unless the command is a recovery probe
+func (b *redisCircuitBreaker) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	if ctx.Value(redisProbeContextKey{}) == nil && !IsRedisAvailable() {
+		return ctx, ErrRedisUnavailable
+	}
+
+	return ctx, nil
+}
+
+// AfterProcess Records the result of a command
+func (b *redisCircuitBreaker) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if ctx.Value(redisProbeContextKey{}) == nil {
+		b.recordResult(cmd.Err())
+	}
+
+	return nil
+}
+
+// BeforeProcessPipeline Fails fast while the breaker is open
+func (b *redisCircuitBreaker) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	if !IsRedisAvailable() {
+		return ctx, ErrRedisUnavailable
+	}
+
+	return ctx, nil
+}
+
+// AfterProcessPipeline Records the result of the first failed command in a pipeline
+func (b *redisCircuitBreaker) AfterProcessPipeline(_ context.Context, cmds []redis.Cmder) error {
+	var err error
+
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+			break
+		}
+	}
+
+	b.recordResult(err)
+	return nil
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"example.com/Quaver/Z/config"
+	"strings"
+)
+
+// KeyPrefix The prefix of every redis key owned by the server. Changing it lets multiple environments
+// (ex. staging and production) share the same redis instance.
+var KeyPrefix = "quaver:server:"
+
+// RedisKey Builds a server redis key by joining the parts onto the key prefix
+func RedisKey(parts ...string) string {
+	return KeyPrefix + strings.Join(parts, ":")
+}
+
+// Applies the key prefix from the config, if one is set
+func loadKeyPrefix() {
+	if config.Instance == nil || config.Instance.Redis.KeyPrefix == "" {
+		return
+	}
+
+	KeyPrefix = config.Instance.Redis.KeyPrefix
+
+	if !strings.HasSuffix(KeyPrefix, ":") {
+		KeyPrefix += ":"
+	}
+}
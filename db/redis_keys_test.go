@@ -0,0 +1,17 @@
+package db
+
+import "testing"
+
+func TestRedisKey(t *testing.T) {
+	defer func(prefix string) { KeyPrefix = prefix }(KeyPrefix)
+
+	if key := RedisKey("session", "abc"); key != "quaver:server:session:abc" {
+		t.Fatalf("expected the default prefix to be kept, got %v", key)
+	}
+
+	KeyPrefix = "staging:"
+
+	if key := RedisKey("multiplayer", "1", "player", "2"); key != "staging:multiplayer:1:player:2" {
+		t.Fatalf("expected the key to use the configured prefix, got %v", key)
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"example.com/Quaver/Z/config"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Counts the KEYS commands sent to redis
+type keysCommandCounter struct {
+	count int32
+}
+
+func (h *keysCommandCounter) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if strings.EqualFold(cmd.Name(), "keys") {
+		atomic.AddInt32(&h.count, 1)
+	}
+
+	return ctx, nil
+}
+
+func (h *keysCommandCounter) AfterProcess(context.Context, redis.Cmder) error {
+	return nil
+}
+
+func (h *keysCommandCounter) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	for _, cmd := range cmds {
+		_, _ = h.BeforeProcess(ctx, cmd)
+	}
+
+	return ctx, nil
+}
+
+func (h *keysCommandCounter) AfterProcessPipeline(context.Context, []redis.Cmder) error {
+	return nil
+}
+
+func TestClearRedisKeysWithPattern(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Instance == nil {
+		return
+	}
+
+	InitializeRedis()
+
+	const count = redisScanBatchSize*2 + 500
+	pipe := Redis.Pipeline()
+
+	for i := 0; i < count; i++ {
+		pipe.Set(RedisCtx, fmt.Sprintf("quaver:test:clear_pattern:%v", i), i, 0)
+	}
+
+	if _, err := pipe.Exec(RedisCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &keysCommandCounter{}
+	Redis.AddHook(counter)
+
+	if err := ClearRedisKeysWithPattern("quaver:test:clear_pattern:*"); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&counter.count) != 0 {
+		t.Fatal("expected keys to be cleared without a KEYS command")
+	}
+
+	remaining := 0
+	iter := Redis.Scan(RedisCtx, 0, "quaver:test:clear_pattern:*", redisScanBatchSize).Iterator()
+
+	for iter.Next(RedisCtx) {
+		remaining++
+	}
+
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining != 0 {
+		t.Fatalf("expected all keys to be cleared, %v remain", remaining)
+	}
+}
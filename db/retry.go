@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	retryMaxAttempts = 3                     // The amount of times a command is attempted before giving up
+	retryBaseDelay   = 50 * time.Millisecond // The delay before the first retry, doubled after each attempt
+)
+
+// WithRetry Runs a redis operation, retrying it with exponential backoff if it fails because redis couldn't be
+// reached. Errors returned by redis itself, cancelled contexts and an open circuit breaker are returned right away.
+func WithRetry(fn func() error) error {
+	delay := retryBaseDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+
+		if attempt == retryMaxAttempts || !isTransientRedisError(err) {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Returns if an error is a brief connection problem that is worth retrying
+func isTransientRedisError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	return isRedisConnectionError(err)
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"io"
+	"testing"
+)
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"success", nil, 1},
+		{"connection error", io.EOF, retryMaxAttempts},
+		{"redis reply error", redis.Nil, 1},
+		{"breaker open", ErrRedisUnavailable, 1},
+		{"context cancelled", context.Canceled, 1},
+	}
+
+	for _, test := range tests {
+		attempts := 0
+
+		err := WithRetry(func() error {
+			attempts++
+			return test.err
+		})
+
+		if err != test.err {
+			t.Fatalf("%v: expected %v, got %v", test.name, test.err, err)
+		}
+
+		if attempts != test.attempts {
+			t.Fatalf("%v: expected %v attempts, got %v", test.name, test.attempts, attempts)
+		}
+	}
+}
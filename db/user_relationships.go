@@ -24,6 +24,21 @@ func GetUserFriendsList(userId int) ([]int, error) {
 	return relationships, nil
 }
 
+// GetUserBlockedList Retrieves a slice of user ids that a given user has blocked
+func GetUserBlockedList(userId int) ([]int, error) {
+	const query string = "SELECT target_user_id FROM user_relationships WHERE user_id = ? AND (relationship & 2) != 0"
+
+	relationships := make([]int, 0)
+
+	err := SQL.Select(&relationships, query, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return relationships, nil
+}
+
 // GetUserRelationship Gets a relationship with a user
 func GetUserRelationship(userId int, targetUserId int) (*UserRelationship, error) {
 	const query string = "SELECT * FROM user_relationships WHERE user_id = ? AND target_user_id = ? LIMIT 1"
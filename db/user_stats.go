@@ -129,8 +129,8 @@ func getRankFromRedis(key string, userId int) (int, error) {
 	result, err := Redis.ZRevRank(RedisCtx, key, strconv.Itoa(userId)).Result()
 
 	if err != nil {
-		// Rank does not exist in the database
-		if err == redis.Nil {
+		// Rank does not exist in the database, or redis is down and ranks are unknown for now.
+		if err == redis.Nil || err == ErrRedisUnavailable {
 			return -1, nil
 		}
 
@@ -2,14 +2,19 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/config"
 	"fmt"
 	"github.com/Philipp15b/go-steamapi"
+	"github.com/go-sql-driver/mysql"
+	"log"
 	"strconv"
 	"time"
 )
 
+const mysqlErrorUnknownColumn uint16 = 1054 // ER_BAD_FIELD_ERROR
+
 type User struct {
 	Id             int               `db:"id"`
 	SteamId        string            `db:"steam_id"`
@@ -18,6 +23,8 @@ type User struct {
 	Privileges     common.Privileges `db:"privileges"`
 	UserGroups     common.UserGroups `db:"usergroups"`
 	MuteEndTime    int64             `db:"mute_endtime"`
+	MuteReason     sql.NullString    `db:"mute_reason"`
+	MutedById      sql.NullInt64     `db:"muted_by"`
 	Country        string            `db:"country"`
 	AvatarUrl      sql.NullString    `db:"avatar_url"`
 	TwitchUsername sql.NullString    `db:"twitch_username"`
@@ -39,6 +46,7 @@ func GetUserBySteamId(steamId string) (*User, error) {
 		return nil, err
 	}
 
+	loadUserMuteDetails(&user)
 	return &user, nil
 }
 
@@ -53,9 +61,25 @@ func GetUserByUsername(username string) (*User, error) {
 		return nil, err
 	}
 
+	loadUserMuteDetails(&user)
 	return &user, nil
 }
 
+// Loads why a muted user was muted and who muted them. The mute_reason and muted_by columns are newer than the
+// rest of the users table, so they're read separately to keep logins working on a database that hasn't been
+// migrated yet (see the README). The user is still muted in that case, just without the details.
+func loadUserMuteDetails(user *User) {
+	if user.MuteEndTime <= time.Now().UnixMilli() {
+		return
+	}
+
+	err := SQL.QueryRow("SELECT mute_reason, muted_by FROM users WHERE id = ? LIMIT 1", user.Id).Scan(&user.MuteReason, &user.MutedById)
+
+	if err != nil {
+		log.Printf("Failed to fetch the mute details of user #%v - %v\n", user.Id, err)
+	}
+}
+
 // UpdateUserLatestActivity Updates the latest_activity of a user to the current time
 func UpdateUserLatestActivity(id int) error {
 	_, err := SQL.Exec("UPDATE users SET latest_activity = ? WHERE id = ?", time.Now().UnixMilli(), id)
@@ -97,9 +121,16 @@ func UpdateUserSteamAvatar(steamId string) (string, error) {
 	return avatar, nil
 }
 
-// MuteUser Mutes a user for a given duration
-func MuteUser(id int, endTime int64) error {
-	_, err := SQL.Exec("UPDATE users SET mute_endtime = ? WHERE id = ?", endTime, id)
+// MuteUser Mutes a user until a given time, storing the reason and the id of the user who muted them.
+// An empty reason or a byId of 0 are stored as NULL.
+func MuteUser(id int, endTime int64, reason string, byId int) error {
+	_, err := SQL.Exec("UPDATE users SET mute_endtime = ?, mute_reason = ?, muted_by = ? WHERE id = ?", endTime,
+		sql.NullString{String: reason, Valid: reason != ""}, sql.NullInt64{Int64: int64(byId), Valid: byId != 0}, id)
+
+	// The mute still goes through on a database without the mute_reason and muted_by columns
+	if isUnknownColumnError(err) {
+		_, err = SQL.Exec("UPDATE users SET mute_endtime = ? WHERE id = ?", endTime, id)
+	}
 
 	if err != nil {
 		return err
@@ -108,6 +139,12 @@ func MuteUser(id int, endTime int64) error {
 	return nil
 }
 
+// Returns if a query failed because it used a column that doesn't exist
+func isUnknownColumnError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrorUnknownColumn
+}
+
 // UnlinkUserTwitch Unlinks the twitch account of a given user
 func UnlinkUserTwitch(id int) error {
 	_, err := SQL.Exec("UPDATE users SET twitch_username = NULL WHERE id = ?", id)
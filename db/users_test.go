@@ -1,7 +1,10 @@
 package db
 
 import (
+	"database/sql"
 	"example.com/Quaver/Z/config"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"testing"
 )
 
@@ -58,3 +61,15 @@ func TestUpdateUserSteamAvatar(t *testing.T) {
 
 	CloseSQLConnection()
 }
+
+func TestIsUnknownColumnError(t *testing.T) {
+	unknownColumn := &mysql.MySQLError{Number: 1054, Message: "Unknown column 'mute_reason' in 'field list'"}
+
+	if !isUnknownColumnError(unknownColumn) || !isUnknownColumnError(fmt.Errorf("muting user: %w", unknownColumn)) {
+		t.Fatal("expected an unknown column error to be detected")
+	}
+
+	if isUnknownColumnError(&mysql.MySQLError{Number: 1062}) || isUnknownColumnError(sql.ErrNoRows) || isUnknownColumnError(nil) {
+		t.Fatal("expected other errors not to be treated as unknown column errors")
+	}
+}
@@ -0,0 +1,222 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot A point-in-time dump of the server state used for post-mortem debugging
+type Snapshot struct {
+	Timestamp      int64            `json:"timestamp"`
+	OnlineUsers    int              `json:"online_users"`
+	LobbyUsers     int              `json:"lobby_users"`
+	Users          []*UserSnapshot  `json:"users"`
+	Games          []*GameSnapshot  `json:"games"`
+	RedisKeyCounts map[string]int64 `json:"redis_key_counts"`
+	Metrics        map[string]int64 `json:"metrics"`
+}
+
+// UserSnapshot The state of an online user. The ip address is always redacted.
+type UserSnapshot struct {
+	Id                 int                      `json:"id"`
+	Username           string                   `json:"username"`
+	IpAddress          string                   `json:"ip_address"`
+	UserAgent          string                   `json:"user_agent"`
+	ConnectedTimestamp int64                    `json:"connected_timestamp"`
+	LastPongTimestamp  int64                    `json:"last_pong_timestamp"`
+	SendFailures       int                      `json:"send_failures"`
+	MultiplayerGameId  int                      `json:"multiplayer_game_id"`
+	Status             objects.ClientStatusType `json:"status"`
+	Spectators         int                      `json:"spectators"`
+	Spectating         int                      `json:"spectating"`
+	ReplayFrames       int                      `json:"replay_frames"`
+}
+
+// GameSnapshot The state of a multiplayer game. The password is never included.
+type GameSnapshot struct {
+	Id               int    `json:"id"`
+	Name             string `json:"name"`
+	HostId           int    `json:"host_id"`
+	RefereeIds       []int  `json:"referee_ids"`
+	CreatorId        int    `json:"creator_id"`
+	PlayerIds        []int  `json:"player_ids"`
+	PlayersReady     []int  `json:"players_ready"`
+	Spectators       int    `json:"spectators"`
+	MapId            int    `json:"map_id"`
+	MapMD5           string `json:"map_md5"`
+	InProgress       bool   `json:"in_progress"`
+	HasPassword      bool   `json:"has_password"`
+	IsTournamentMode bool   `json:"is_tournament_mode"`
+	IsAutoHost       bool   `json:"is_auto_host"`
+}
+
+// Returns the redis key patterns that are counted in a snapshot
+func getRedisKeyPatterns() []string {
+	return []string{
+		db.RedisKey("session", "*"),
+		db.RedisKey("user_status", "*"),
+		db.RedisKey("multiplayer", "*"),
+	}
+}
+
+// Initialize Adds the chat command used to dump the server state
+func Initialize() {
+	chat.AddPrivateMessageHandler(handleDumpStateCommand)
+}
+
+// CreateSnapshot Creates a snapshot of the current server state. Each registry is read under its own lock.
+func CreateSnapshot() *Snapshot {
+	snapshot := &Snapshot{
+		Timestamp:      time.Now().UnixMilli(),
+		Users:          []*UserSnapshot{},
+		Games:          []*GameSnapshot{},
+		RedisKeyCounts: map[string]int64{},
+		Metrics:        metrics.GetCounters(),
+	}
+
+	for _, user := range sessions.GetOnlineUsers() {
+		snapshot.Users = append(snapshot.Users, &UserSnapshot{
+			Id:                 user.Info.Id,
+			Username:           user.Info.Username,
+			IpAddress:          utils.RedactIpAddress(user.GetIpAddress()),
+			UserAgent:          user.GetUserAgent(),
+			ConnectedTimestamp: user.GetConnectedTimestamp(),
+			LastPongTimestamp:  user.GetLastPongTimestamp(),
+			SendFailures:       user.GetSendFailureCount(),
+			MultiplayerGameId:  user.GetMultiplayerGameId(),
+			Status:             user.GetClientStatus().Status,
+			Spectators:         len(user.GetSpectators()),
+			Spectating:         len(user.GetSpectating()),
+			ReplayFrames:       user.GetReplayFrameCount(),
+		})
+	}
+
+	snapshot.OnlineUsers = len(snapshot.Users)
+	snapshot.LobbyUsers = multiplayer.GetLobbyUserCount()
+
+	for _, game := range multiplayer.GetGames() {
+		game.RunLocked(func() {
+			snapshot.Games = append(snapshot.Games, &GameSnapshot{
+				Id:               game.Data.Id,
+				Name:             game.Data.Name,
+				HostId:           game.Data.HostId,
+				RefereeIds:       append([]int{}, game.Data.RefereeIds...),
+				CreatorId:        game.CreatorId,
+				PlayerIds:        append([]int{}, game.Data.PlayerIds...),
+				PlayersReady:     append([]int{}, game.Data.PlayersReady...),
+				Spectators:       game.GetSpectatorCount(),
+				MapId:            game.Data.MapId,
+				MapMD5:           game.Data.MapMD5,
+				InProgress:       game.Data.InProgress,
+				HasPassword:      game.Data.HasPassword,
+				IsTournamentMode: game.Data.IsTournamentMode,
+				IsAutoHost:       game.Data.IsAutoHost,
+			})
+		})
+	}
+
+	if db.Redis != nil {
+		if size, err := db.Redis.DBSize(db.RedisCtx).Result(); err == nil {
+			snapshot.RedisKeyCounts["total"] = size
+		}
+
+		for _, pattern := range getRedisKeyPatterns() {
+			count, err := countRedisKeys(pattern)
+
+			if err != nil {
+				log.Printf("Failed to count redis keys for diagnostics - %v\n", err)
+				continue
+			}
+
+			snapshot.RedisKeyCounts[pattern] = count
+		}
+	}
+
+	return snapshot
+}
+
+// WriteSnapshot Creates a snapshot of the server state and writes it to the diagnostics directory
+func WriteSnapshot() (string, error) {
+	data, err := json.MarshalIndent(CreateSnapshot(), "", "    ")
+
+	if err != nil {
+		return "", err
+	}
+
+	directory := os.TempDir()
+
+	if config.Instance != nil && config.Instance.DiagnosticsDirectory != "" {
+		directory = config.Instance.DiagnosticsDirectory
+	}
+
+	err = os.MkdirAll(directory, 0755)
+
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(directory, fmt.Sprintf("z-state-%v.json", time.Now().UnixMilli()))
+	err = os.WriteFile(path, data, 0600)
+
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Counts the amount of redis keys that match a pattern without blocking the server
+func countRedisKeys(pattern string) (int64, error) {
+	var cursor uint64
+	var count int64
+
+	for {
+		keys, next, err := db.Redis.Scan(db.RedisCtx, cursor, pattern, 1000).Result()
+
+		if err != nil {
+			return 0, err
+		}
+
+		count += int64(len(keys))
+		cursor = next
+
+		if cursor == 0 {
+			return count, nil
+		}
+	}
+}
+
+// Handles the command to dump the server state. Only works when messaging the bot directly.
+func handleDumpStateCommand(user *sessions.User, receivingUser *sessions.User, args []string) string {
+	if receivingUser != chat.Bot || len(args) == 0 || args[0] != "!dumpstate" {
+		return ""
+	}
+
+	if !common.HasUserGroup(user.Info.UserGroups, common.UserGroupDeveloper) &&
+		!common.HasPrivilege(user.Info.Privileges, common.PrivilegeViewAdminLogs) {
+		return ""
+	}
+
+	path, err := WriteSnapshot()
+
+	if err != nil {
+		log.Printf("Failed to write server state dump - %v\n", err)
+		return "An error occurred while dumping the server state."
+	}
+
+	log.Printf("[%v #%v] Dumped server state to: %v\n", user.Info.Username, user.Info.Id, path)
+	return fmt.Sprintf("The server state has been dumped to: %v", path)
+}
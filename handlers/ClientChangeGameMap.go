@@ -4,6 +4,7 @@ import (
 	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"fmt"
 )
 
 // Handles when the client is requesting to change the map for their MP game.
@@ -19,6 +20,15 @@ func handleClientChangeGameMap(user *sessions.User, packet *packets.ClientChange
 	}
 
 	game.RunLocked(func() {
+		// Selecting a map during a map vote proposes it instead
+		if game.IsMapVoteActive() {
+			if err := game.ProposeMap(user, packet); err != nil {
+				sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to propose the map: %v.", err)), user)
+			}
+
+			return
+		}
+
 		game.ChangeMap(user, packet)
 	})
 }
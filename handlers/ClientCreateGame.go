@@ -13,6 +13,8 @@ func handleClientCreateGame(user *sessions.User, packet *packets.ClientCreateGam
 		return
 	}
 
+	// The game only keeps a hash of the password, so the creator joins with the one they sent
+	password := packet.Game.CreationPassword
 	game, err := multiplayer.NewGame(packet.Game, user.Info.Id)
 
 	if err != nil {
@@ -23,6 +25,7 @@ func handleClientCreateGame(user *sessions.User, packet *packets.ClientCreateGam
 	multiplayer.AddGameToLobby(game)
 
 	game.RunLocked(func() {
-		game.AddPlayer(user.Info.Id, game.Password)
+		multiplayer.AnnounceGameCreation(game)
+		game.AddPlayer(user.Info.Id, password)
 	})
 }
@@ -33,6 +33,14 @@ func handleClientFriendship(user *sessions.User, packet *packets.ClientFriendshi
 
 		if err != nil {
 			log.Printf("Failed to add friend (#%v -> #%v) - %v\n", user.Info.Id, packet.UserId, err)
+			return
+		}
+
+		user.AddFriendId(packet.UserId)
+
+		// The new friend may already be online, so they won't send a presence update until their status changes
+		if friend := sessions.GetUserById(packet.UserId); friend != nil && !friend.IsInvisible() {
+			sessions.SendPacketToUser(packets.NewServerUserStatusSingle(friend.Info.Id, friend.GetClientStatus()), user)
 		}
 	case packets.FriendsListActionRemove:
 		if relationship == nil {
@@ -43,6 +51,9 @@ func handleClientFriendship(user *sessions.User, packet *packets.ClientFriendshi
 
 		if err != nil {
 			log.Printf("Failed to remove friend (#%v -> #%v) - #%v\n", user.Info.Id, packet.UserId, err)
+			return
 		}
+
+		user.RemoveFriendId(packet.UserId)
 	}
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests to change the health type of a multiplayer game
+func handleClientGameChangeHealthType(user *sessions.User, packet *packets.ClientGameChangeHealthType) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.SetHealthType(user, packet.HealthType)
+	})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests to change the amount of lives players have in a multiplayer game
+func handleClientGameChangeLivesCount(user *sessions.User, packet *packets.ClientGameChangeLivesCount) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.SetLifeCount(user, packet.Lives)
+	})
+}
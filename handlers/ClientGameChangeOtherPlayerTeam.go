@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the host requests to move another player onto a different team in a multiplayer game
+func handleClientGameChangeOtherPlayerTeam(user *sessions.User, packet *packets.ClientGameChangeOtherPlayerTeam) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.ChangePlayerTeam(user, packet.UserId, packet.Team)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"fmt"
 )
 
 // Handles when the client requests to change their modifiers in multiplayer
@@ -19,6 +20,8 @@ func handleClientGameChangePlayerModifiers(user *sessions.User, packet *packets.
 	}
 
 	game.RunLocked(func() {
-		game.SetPlayerModifiers(user.Info.Id, packet.Modifiers)
+		if err := game.SetPlayerModifiers(user.Info.Id, packet.Modifiers); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to change modifiers: %v.", err)), user)
+		}
 	})
 }
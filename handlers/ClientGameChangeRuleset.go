@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests to change the ruleset (free-for-all or team) of a multiplayer game
+func handleClientGameChangeRuleset(user *sessions.User, packet *packets.ClientGameChangeRuleset) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.SetRuleset(user, packet.Ruleset)
+	})
+}
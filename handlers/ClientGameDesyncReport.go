@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client reports that it is out of sync with its multiplayer game
+func handleClientGameDesyncReport(user *sessions.User, packet *packets.ClientGameDesyncReport) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetUserGame(user)
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.HandleDesyncReport(user)
+	})
+}
@@ -25,6 +25,8 @@ func handleClientGameInvite(user *sessions.User, packet *packets.ClientGameInvit
 	}
 
 	game.RunLocked(func() {
-		game.SendInvite(user, invitee)
+		if err := game.SendInvite(user, invitee); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(err.Error()), user)
+		}
 	})
 }
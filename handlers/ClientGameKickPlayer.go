@@ -4,6 +4,7 @@ import (
 	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"fmt"
 )
 
 // Handles when the client is requesting to kick someone from a multiplayer game
@@ -19,6 +20,8 @@ func handleClientGameKickPlayer(user *sessions.User, packet *packets.ClientGameK
 	}
 
 	game.RunLocked(func() {
-		game.KickPlayer(user, packet.UserId)
+		if err := game.KickPlayer(user, packet.UserId, false); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to kick player: %v.", err)), user)
+		}
 	})
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client reports how far along they are in downloading the current map in multiplayer
+func handleClientGameMapDownloadProgress(user *sessions.User, packet *packets.ClientGameMapDownloadProgress) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.SetPlayerDownloadProgress(user.Info.Id, packet.Progress)
+	})
+}
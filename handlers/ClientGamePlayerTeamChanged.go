@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests to switch their own team in a multiplayer game
+func handleClientGamePlayerTeamChanged(user *sessions.User, packet *packets.ClientGamePlayerTeamChanged) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetGameById(user.GetMultiplayerGameId())
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.ChangePlayerTeam(user, user.Info.Id, packet.Team)
+	})
+}
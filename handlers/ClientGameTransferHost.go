@@ -4,6 +4,7 @@ import (
 	"example.com/Quaver/Z/multiplayer"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"fmt"
 )
 
 // Handles when the client wishes to transfer host to another player
@@ -19,6 +20,8 @@ func handleClientGameTransferHost(user *sessions.User, packet *packets.ClientGam
 	}
 
 	game.RunLocked(func() {
-		game.SetHost(user, packet.UserId)
+		if err := game.TransferHost(user, packet.UserId); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to transfer host: %v.", err)), user)
+		}
 	})
 }
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client reports its current performance
+func handleClientPerformanceReport(user *sessions.User, packet *packets.ClientPerformanceReport) {
+	if packet == nil {
+		return
+	}
+
+	user.SetReportedFps(packet.Fps)
+}
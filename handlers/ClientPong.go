@@ -17,6 +17,10 @@ func handleClientPong(user *sessions.User, packet *packets.ClientPong) {
 
 	user.SetLastPongTimestamp()
 
+	if err := sessions.RefreshRedisUserToken(user); err != nil {
+		log.Printf("[%v - %v] Failed to refresh session token - %v\n", user.Info.Username, user.Info.Id, err)
+	}
+
 	packetProcs := packet.ParseProcessList()
 
 	if packetProcs == nil || len(packetProcs) == 0 {
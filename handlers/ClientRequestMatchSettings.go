@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests the current settings of their multiplayer game to resync
+func handleClientRequestMatchSettings(user *sessions.User, packet *packets.ClientRequestMatchSettings) {
+	if packet == nil {
+		return
+	}
+
+	game := multiplayer.GetUserGame(user)
+
+	if game == nil {
+		return
+	}
+
+	game.RunLocked(func() {
+		game.SendMatchSettings(user)
+	})
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests the current state of their inbound packet budget
+func handleClientRequestRateLimitStatus(user *sessions.User, packet *packets.ClientRequestRateLimitStatus) {
+	if packet == nil {
+		return
+	}
+
+	if !user.HasCapability(sessions.CapabilityRateLimitStatus) {
+		return
+	}
+
+	user.SendPacketBudget()
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Handles when the client requests their own up-to-date session info
+func handleClientRequestSessionInfo(user *sessions.User, packet *packets.ClientRequestSessionInfo) {
+	if packet == nil {
+		return
+	}
+
+	gameId := ""
+
+	if game := multiplayer.GetUserGame(user); game != nil {
+		gameId = game.Data.GameId
+	}
+
+	sessions.SendPacketToUser(packets.NewServerSessionInfo(user.SerializeForPacket(), user.GetMuteTimeRemaining(), gameId), user)
+}
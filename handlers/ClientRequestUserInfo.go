@@ -12,22 +12,22 @@ func handleClientRequestUserInfo(user *sessions.User, packet *packets.ClientRequ
 		return
 	}
 
-	userInfo := getPacketUsersFromUserIds(packet.UserIds)
+	userInfo := getPacketUsersFromUserIds(user, packet.UserIds)
 	sessions.SendPacketToUser(packets.NewServerUserInfo(userInfo), user)
 }
 
-// Converts a slice of user ids into their respective packet users.
-func getPacketUsersFromUserIds(userIds []int) []*objects.PacketUser {
+// Converts a slice of user ids into their respective packet users, as seen by the requesting user
+func getPacketUsersFromUserIds(requester *sessions.User, userIds []int) []*objects.PacketUser {
 	var userInfo []*objects.PacketUser
 
 	for _, id := range userIds {
 		user := sessions.GetUserById(id)
 
-		if user == nil {
+		if user == nil || user.IsInvisible() {
 			continue
 		}
 
-		userInfo = append(userInfo, user.SerializeForPacket())
+		userInfo = append(userInfo, user.SerializeForViewer(requester))
 	}
 
 	return userInfo
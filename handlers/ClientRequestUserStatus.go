@@ -15,13 +15,13 @@ func handleClientRequestUserStatus(user *sessions.User, packet *packets.ClientRe
 	var statuses packets.ClientStatus = map[int]*objects.ClientStatus{}
 
 	for _, userId := range packet.UserIds {
-		user := sessions.GetUserById(userId)
+		target := sessions.GetUserById(userId)
 
-		if user == nil {
+		if target == nil || (target.IsInvisible() && target != user) {
 			continue
 		}
 
-		statuses[userId] = user.GetClientStatus()
+		statuses[userId] = target.GetClientStatus()
 	}
 
 	sessions.SendPacketToUser(packets.NewServerUserStatus(statuses), user)
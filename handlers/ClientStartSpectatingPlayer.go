@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"time"
 )
 
 // Handles when the client requests to start spectating a player.
@@ -11,6 +13,11 @@ func handleClientStartSpectatingPlayer(user *sessions.User, packet *packets.Clie
 		return
 	}
 
+	if !user.TrySpectateSwitch(getSpectateSwitchCooldown()) {
+		sessions.SendPacketToUser(packets.NewServerNotificationError("You are switching spectator targets too quickly. Please slow down."), user)
+		return
+	}
+
 	user.StopSpectatingAll()
 
 	spectatee := sessions.GetUserById(packet.UserId)
@@ -21,3 +28,12 @@ func handleClientStartSpectatingPlayer(user *sessions.User, packet *packets.Clie
 
 	spectatee.AddSpectator(user)
 }
+
+// Returns how long a user has to wait between switching spectator targets
+func getSpectateSwitchCooldown() time.Duration {
+	if config.Instance == nil || config.Instance.SpectateSwitchCooldown <= 0 {
+		return time.Second
+	}
+
+	return time.Duration(config.Instance.SpectateSwitchCooldown) * time.Millisecond
+}
@@ -18,4 +18,8 @@ func handleClientStatusUpdate(user *sessions.User, packet *packets.ClientStatusU
 
 	user.SetClientStatus(&packet.Status)
 	user.SendClientStatusToSpectators()
+
+	if sessions.IsPrimarySession(user) {
+		_ = sessions.BroadcastToFriends(user.Info.Id, packets.NewServerUserStatusSingle(user.Info.Id, user.GetClientStatus()))
+	}
 }
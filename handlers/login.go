@@ -8,6 +8,7 @@ import (
 	"example.com/Quaver/Z/common"
 	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
@@ -34,6 +35,9 @@ type LoginData struct {
 
 	// Game Client file signatures
 	Client string `json:"client"`
+
+	// Optional features the client supports
+	Capabilities []string `json:"caps"`
 }
 
 // HandleLogin Handles the login of a client
@@ -89,12 +93,7 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 		}
 	}
 
-	ip := conn.RemoteAddr().String()
-	ipHeader := r.Header.Get("X-Forwarded-For")
-
-	if ipHeader != "" {
-		ip = ipHeader
-	}
+	ip := utils.GetRequestIpAddress(r)
 
 	err = db.InsertLoginIpAddress(user.Id, ip)
 
@@ -114,19 +113,13 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 		log.Println("Failed to update steam avatar: ", err)
 	}
 
-	err = removePreviousLoginSession(user)
-
-	if err != nil {
-		return err
-	}
+	removePreviousLoginSession(user)
 
 	sessionUser := sessions.NewUser(conn, user)
+	sessionUser.SetConnectionInfo(ip, r.UserAgent())
+	sessionUser.SetCapabilities(data.Capabilities)
 
-	err = sessionUser.SetStats()
-
-	if err != nil {
-		return err
-	}
+	sessionUser.LoadStats()
 
 	err = sessions.AddUser(sessionUser)
 
@@ -140,7 +133,9 @@ func HandleLogin(conn net.Conn, r *http.Request) error {
 		return err
 	}
 
-	log.Printf("[%v #%v] Logged in (%v users online).\n", user.Username, user.Id, sessions.GetOnlineUserCount())
+	sessionUser.SetAuthenticated()
+
+	log.Printf("[%v #%v] Logged in from %v (%v) (%v users online).\n", user.Username, user.Id, ip, r.UserAgent(), sessions.GetOnlineUserCount())
 	return nil
 }
 
@@ -371,23 +366,21 @@ func updateUserAvatar(user *db.User) error {
 	return nil
 }
 
-// Checks to see if the user is already logged in and removes the previous sesion
-func removePreviousLoginSession(user *db.User) error {
-	u := sessions.GetUserById(user.Id)
+// Checks to see if the user is already logged in and removes their oldest session if they have reached
+// the maximum amount of sessions
+func removePreviousLoginSession(user *db.User) {
+	existing := sessions.GetUserSessions(user.Id)
 
-	if u == nil {
-		return nil
+	if len(existing) == 0 || len(existing) < getMaxSessionsPerUser() {
+		return
 	}
 
-	err := sessions.RemoveUser(u)
-
-	if err != nil {
-		return err
-	}
+	u := existing[0]
 
+	// Packets are written synchronously, so the notice is sent before the session is torn down.
+	// Disconnecting runs the disconnect handlers, so the old session leaves its chat channels and multiplayer game.
 	sessions.SendPacketToUser(packets.NewServerNotificationError("You are being logged out due to logging in from a different location"), u)
-	utils.CloseConnectionDelayed(u.Conn)
-	return nil
+	u.Disconnect()
 }
 
 // Sends initial packets to log the user in
@@ -396,7 +389,12 @@ func sendLoginPackets(user *sessions.User) error {
 	sessions.SendPacketToUser(packets.NewServerUsersOnline(sessions.GetOnlineUserIds()), user)
 	sessions.SendPacketToUser(packets.NewServerUserInfo(sessions.GetSerializedOnlineUsers()), user)
 	sessions.SendPacketToUser(packets.NewServerTwitchConnection(user.Info.TwitchUsername.String), user)
-	sessions.SendPacketToAllUsers(packets.NewServerUserConnected(user.SerializeForPacket()))
+	err := sessions.SendPacketToAllUsers(packets.NewServerUserConnected(user.SerializeForPacket()))
+
+	if err != nil {
+		return err
+	}
+
 	joinChatChannels(user)
 
 	friends, err := db.GetUserFriendsList(user.Info.Id)
@@ -405,10 +403,64 @@ func sendLoginPackets(user *sessions.User) error {
 		return err
 	}
 
+	user.SetFriendIds(friends)
+
+	blocked, err := db.GetUserBlockedList(user.Info.Id)
+
+	if err != nil {
+		return err
+	}
+
+	user.SetBlockedIds(blocked)
 	sessions.SendPacketToUser(packets.NewServerFriendsList(friends), user)
+	sendFriendStatuses(user, friends)
 	return nil
 }
 
+// Sends the user the statuses of their friends who were already online before they logged in,
+// and when each of their offline friends was last online
+func sendFriendStatuses(user *sessions.User, friends []int) {
+	var statuses packets.ClientStatus = map[int]*objects.ClientStatus{}
+	offline := make([]int, 0)
+
+	for _, id := range friends {
+		friend := sessions.GetUserById(id)
+
+		if friend == nil || friend.IsInvisible() {
+			offline = append(offline, id)
+			continue
+		}
+
+		statuses[id] = friend.GetClientStatus()
+	}
+
+	if len(statuses) > 0 {
+		sessions.SendPacketToUser(packets.NewServerUserStatus(statuses), user)
+	}
+
+	sendFriendsLastSeen(user, offline)
+}
+
+// Sends the user when each of their offline friends was last online. Friends who have never been seen are skipped.
+func sendFriendsLastSeen(user *sessions.User, friends []int) {
+	if len(friends) == 0 || !db.IsRedisAvailable() {
+		return
+	}
+
+	lastSeen, err := sessions.GetLastSeenMany(friends)
+
+	if err != nil {
+		log.Printf("[%v #%v] Failed to fetch when friends were last seen - %v\n", user.Info.Username, user.Info.Id, err)
+		return
+	}
+
+	for _, id := range friends {
+		if seen, ok := lastSeen[id]; ok {
+			sessions.SendPacketToUser(packets.NewServerUserDisconnected(id, seen.UnixMilli()), user)
+		}
+	}
+}
+
 // Joins an available chat channel
 func joinChatChannels(user *sessions.User) {
 	channels := chat.GetAvailableChannels(user.Info.UserGroups)
@@ -426,3 +478,12 @@ func joinChatChannels(user *sessions.User) {
 func logFailedLogin(conn net.Conn, err error) error {
 	return fmt.Errorf("[%v] login failed - %v", conn.RemoteAddr(), err)
 }
+
+// Returns the amount of sessions a user can have at once across devices
+func getMaxSessionsPerUser() int {
+	if config.Instance == nil || config.Instance.Server.MaxSessionsPerUser <= 0 {
+		return 1
+	}
+
+	return config.Instance.Server.MaxSessionsPerUser
+}
@@ -25,6 +25,21 @@ func HandleIncomingPackets(conn net.Conn, msg string) {
 		return
 	}
 
+	if !canHandlePacket(user, p) {
+		log.Printf("[%v #%v] Rejected packet sent before login completed: %v\n", user.Info.Username, user.Info.Id, p.Id)
+		return
+	}
+
+	if p.Id != packets.PacketIdClientPong && p.Id != packets.PacketIdClientLogout && !user.TakePacketToken() {
+		log.Printf("[%v #%v] Dropped packet due to rate limiting: %v\n", user.Info.Username, user.Info.Id, p.Id)
+		return
+	}
+
+	// Pongs are sent automatically by the client, so they don't count as activity
+	if p.Id != packets.PacketIdClientPong {
+		user.MarkActive()
+	}
+
 	switch p.Id {
 	case packets.PacketIdClientPong:
 		handleClientPong(user, unmarshalPacket[packets.ClientPong](msg))
@@ -78,6 +93,16 @@ func HandleIncomingPackets(conn net.Conn, msg string) {
 		handleClientGameChangePlayerModifiers(user, unmarshalPacket[packets.ClientGameChangePlayerModifiers](msg))
 	case packets.PacketIdClientGameChangeAutoHostRotation:
 		handleClientGameHostRotation(user, unmarshalPacket[packets.ClientGameHostRotation](msg))
+	case packets.PacketIdClientGamePlayerTeamChanged:
+		handleClientGamePlayerTeamChanged(user, unmarshalPacket[packets.ClientGamePlayerTeamChanged](msg))
+	case packets.PacketIdClientGameChangeOtherPlayerTeam:
+		handleClientGameChangeOtherPlayerTeam(user, unmarshalPacket[packets.ClientGameChangeOtherPlayerTeam](msg))
+	case packets.PacketIdClientGameChangeRuleset:
+		handleClientGameChangeRuleset(user, unmarshalPacket[packets.ClientGameChangeRuleset](msg))
+	case packets.PacketIdClientGameChangeHealthType:
+		handleClientGameChangeHealthType(user, unmarshalPacket[packets.ClientGameChangeHealthType](msg))
+	case packets.PacketIdClientGameChangeLivesCount:
+		handleClientGameChangeLivesCount(user, unmarshalPacket[packets.ClientGameChangeLivesCount](msg))
 	case packets.PacketIdClientGameChangeMaxPlayers:
 		handleClientGameChangeMaxPlayers(user, unmarshalPacket[packets.ClientGameChangeMaxPlayers](msg))
 	case packets.PacketIdClientGameAcceptInvite:
@@ -116,11 +141,38 @@ func HandleIncomingPackets(conn net.Conn, msg string) {
 		handleClientGameAutoHost(user, unmarshalPacket[packets.ClientGameAutoHost](msg))
 	case packets.PacketIdClientLogout:
 		handleClientLogout(user, unmarshalPacket[packets.ClientLogout](msg))
+	case packets.PacketIdClientRequestMatchSettings:
+		handleClientRequestMatchSettings(user, unmarshalPacket[packets.ClientRequestMatchSettings](msg))
+	case packets.PacketIdClientRequestSessionInfo:
+		handleClientRequestSessionInfo(user, unmarshalPacket[packets.ClientRequestSessionInfo](msg))
+	case packets.PacketIdClientPerformanceReport:
+		handleClientPerformanceReport(user, unmarshalPacket[packets.ClientPerformanceReport](msg))
+	case packets.PacketIdClientRequestRateLimitStatus:
+		handleClientRequestRateLimitStatus(user, unmarshalPacket[packets.ClientRequestRateLimitStatus](msg))
+	case packets.PacketIdClientGameDesyncReport:
+		handleClientGameDesyncReport(user, unmarshalPacket[packets.ClientGameDesyncReport](msg))
+	case packets.PacketIdClientGameMapDownloadProgress:
+		handleClientGameMapDownloadProgress(user, unmarshalPacket[packets.ClientGameMapDownloadProgress](msg))
 	default:
 		log.Println(fmt.Errorf("unknown packet: %v", msg))
 	}
 }
 
+// Returns if a packet can be handled for a user. Until the login handshake completes, the session may only be
+// partially initialized, so only packets that keep the connection alive or close it are handled.
+func canHandlePacket(user *sessions.User, p packets.Packet) bool {
+	if user.IsAuthenticated() {
+		return true
+	}
+
+	switch p.Id {
+	case packets.PacketIdClientPong, packets.PacketIdClientLogout:
+		return true
+	default:
+		return false
+	}
+}
+
 // unmarshalPacket Unmarshal a packet of a specified type
 func unmarshalPacket[T any](packet string) *T {
 	var data T
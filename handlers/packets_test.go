@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"testing"
+)
+
+func TestRejectChatMessageBeforeLogin(t *testing.T) {
+	user := sessions.NewUser(nil, &db.User{Id: 1, Username: "Test"})
+
+	msg, err := json.Marshal(packets.ClientChatMessage{
+		Packet:   packets.Packet{Id: packets.PacketIdClientChatMessage},
+		Receiver: "#lobby",
+		Message:  "hello",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p packets.Packet
+
+	if err := json.Unmarshal(msg, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	if canHandlePacket(user, p) {
+		t.Fatal("expected chat message to be rejected before login completes")
+	}
+
+	if !canHandlePacket(user, packets.Packet{Id: packets.PacketIdClientPong}) {
+		t.Fatal("expected pong to be handled before login completes")
+	}
+
+	user.SetAuthenticated()
+
+	if !canHandlePacket(user, p) {
+		t.Fatal("expected chat message to be handled after login completes")
+	}
+}
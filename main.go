@@ -4,8 +4,10 @@ import (
 	"example.com/Quaver/Z/chat"
 	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/diagnostics"
 	"example.com/Quaver/Z/handlers"
 	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/presence"
 	"example.com/Quaver/Z/webhooks"
 )
 
@@ -23,6 +25,8 @@ func main() {
 	chat.Initialize()
 	multiplayer.InitializeChatBot()
 	multiplayer.InitializeLobby()
+	diagnostics.Initialize()
+	presence.StartAggregator()
 
 	s := NewServer(config.Instance.Server.Port)
 	s.Start()
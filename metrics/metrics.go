@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	counters     = map[string]int64{}
+	countersLock = &sync.Mutex{}
+)
+
+// IncrementCounter Increments a named counter by one
+func IncrementCounter(name string) {
+	AddToCounter(name, 1)
+}
+
+// AddToCounter Adds a value to a named counter
+func AddToCounter(name string, value int64) {
+	countersLock.Lock()
+	defer countersLock.Unlock()
+
+	counters[name] += value
+}
+
+// GetCounter Returns the current value of a named counter
+func GetCounter(name string) int64 {
+	countersLock.Lock()
+	defer countersLock.Unlock()
+
+	return counters[name]
+}
+
+// GetCounters Returns a copy of every counter that has been collected
+func GetCounters() map[string]int64 {
+	countersLock.Lock()
+	defer countersLock.Unlock()
+
+	copied := make(map[string]int64, len(counters))
+
+	for name, value := range counters {
+		copied[name] = value
+	}
+
+	return copied
+}
+
+// GetCounterNames Returns the names of every collected counter in sorted order
+func GetCounterNames() []string {
+	countersLock.Lock()
+	defer countersLock.Unlock()
+
+	names := make([]string, 0, len(counters))
+
+	for name := range counters {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,162 @@
+package multiplayer
+
+import (
+	"fmt"
+
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+)
+
+const (
+	maxPlayerHealth  float64 = 100 // The health every player starts a life with
+	defaultLifeCount int     = 3   // The amount of lives players start a battle royale match with
+	maxLifeCount     int     = 100 // The maximum amount of lives a game can be set to
+)
+
+// How much health each judgement is worth. Negative judgements are always applied, while positive judgements
+// only restore health with the regeneration health type.
+var judgementHealth = map[common.Judgements]float64{
+	common.JudgementMarv:  0.5,
+	common.JudgementPerf:  0.4,
+	common.JudgementGreat: 0.2,
+	common.JudgementGood:  -3,
+	common.JudgementOkay:  -4.5,
+	common.JudgementMiss:  -6,
+}
+
+// The health and remaining lives of a player during a battle royale match
+type playerHealth struct {
+	Health float64
+	Lives  int
+}
+
+// Applies judgements to the player's health. Each time their health runs out, they lose a life and start the
+// next one at full health. Returns if the player has run out of lives.
+func (h *playerHealth) applyJudgements(judgements []common.Judgements, healthType objects.MultiplayerGameHealth) bool {
+	for _, judgement := range judgements {
+		if h.Lives <= 0 {
+			return true
+		}
+
+		delta := judgementHealth[judgement]
+
+		if delta > 0 && healthType != objects.MultiplayerGameHealthRegeneration {
+			continue
+		}
+
+		h.Health = utils.Clamp(h.Health+delta, 0, maxPlayerHealth)
+
+		if h.Health > 0 {
+			continue
+		}
+
+		h.Lives--
+		h.Health = maxPlayerHealth
+	}
+
+	return h.Lives <= 0
+}
+
+// SetHealthType Sets how player health behaves in battle royale matches
+func (game *Game) SetHealthType(requester *sessions.User, healthType objects.MultiplayerGameHealth) {
+	if !game.isUserHost(requester) || game.Data.InProgress {
+		return
+	}
+
+	game.Data.HealthType = healthType
+	game.validateAndCacheSettings()
+
+	game.sendPacketToPlayers(packets.NewServerGameHealthTypeChanged(game.Data.HealthType))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// SetLifeCount Sets the amount of lives players start battle royale matches with
+func (game *Game) SetLifeCount(requester *sessions.User, lives int) {
+	if !game.isUserHost(requester) || game.Data.InProgress {
+		return
+	}
+
+	game.Data.LifeCount = lives
+	game.validateAndCacheSettings()
+
+	game.sendBotMessage(fmt.Sprintf("The life count has been changed to: %v.", game.Data.LifeCount))
+	game.sendPacketToPlayers(packets.NewServerGameLivesChanged(game.Data.LifeCount))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// Gives every player in the match full health and lives, if the match is a battle royale
+func (game *Game) createPlayerHealth() {
+	game.playerHealth = map[int]*playerHealth{}
+	game.playersEliminated = []int{}
+
+	if game.Data.Ruleset != objects.MultiplayerGameRulesetBattleRoyale {
+		return
+	}
+
+	for _, id := range game.playersInMatch {
+		game.playerHealth[id] = &playerHealth{Health: maxPlayerHealth, Lives: game.Data.LifeCount}
+	}
+}
+
+// Applies a player's judgements to their health and eliminates them once they run out of lives
+func (game *Game) updatePlayerHealth(userId int, judgements []common.Judgements) {
+	health, ok := game.playerHealth[userId]
+
+	if !ok || utils.Includes(game.playersEliminated, userId) {
+		return
+	}
+
+	if health.applyJudgements(judgements, game.Data.HealthType) {
+		game.eliminatePlayer(userId)
+	}
+}
+
+// Eliminates a player from the battle royale. The match ends once there is only one player left standing.
+func (game *Game) eliminatePlayer(userId int) {
+	if utils.Includes(game.playersEliminated, userId) {
+		return
+	}
+
+	game.playersEliminated = append(game.playersEliminated, userId)
+	survivors := game.getBattleRoyaleSurvivors()
+
+	// Players are ranked by how long they lasted, so the first player out is ranked last
+	rank := len(survivors) + 1
+
+	if user := sessions.GetUserById(userId); user != nil {
+		game.sendBotMessage(fmt.Sprintf("%v has been eliminated (#%v).", user.Info.Username, rank))
+	}
+
+	game.sendPacketToPlayers(packets.NewServerGamePlayerBattleRoyaleEliminated(userId, rank))
+
+	if len(survivors) <= 1 {
+		game.EndGame(false)
+	}
+}
+
+// Returns the players in the battle royale who haven't been eliminated
+func (game *Game) getBattleRoyaleSurvivors() []int {
+	survivors := make([]int, 0)
+
+	for id := range game.playerHealth {
+		if utils.Includes(game.playersInMatch, id) && !utils.Includes(game.playersEliminated, id) {
+			survivors = append(survivors, id)
+		}
+	}
+
+	return survivors
+}
+
+// Returns the health and lives of a player in a battle royale match, and false if they aren't in one
+func (game *Game) getPlayerHealth(userId int) (playerHealth, bool) {
+	health, ok := game.playerHealth[userId]
+
+	if !ok {
+		return playerHealth{}, false
+	}
+
+	return *health, true
+}
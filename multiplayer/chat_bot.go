@@ -70,7 +70,15 @@ func handleMultiplayerCommands(user *sessions.User, channel *chat.Channel, args
 	game.RunLocked(func() {
 		switch strings.ToLower(args[1]) {
 		case "kick":
-			message = handleCommandKickPlayer(user, game, args)
+			message = handleCommandKickPlayer(user, game, args, false)
+		case "ban":
+			message = handleCommandKickPlayer(user, game, args, true)
+		case "unban":
+			message = handleCommandUnbanPlayer(user, game, args)
+		case "reserve":
+			message = handleCommandReserveSlot(user, game, args, true)
+		case "unreserve":
+			message = handleCommandReserveSlot(user, game, args, false)
 		case "name":
 			message = handleCommandChangeName(user, game, args)
 		case "host":
@@ -79,8 +87,14 @@ func handleMultiplayerCommands(user *sessions.User, channel *chat.Channel, args
 			message = handleCommandChangeMap(user, game, args)
 		case "hostrotation":
 			message = handleCommandHostRotation(user, game)
+		case "autoready":
+			message = handleCommandHostAutoReady(user, game)
 		case "maxplayers":
 			message = handleCommandMaxPlayers(user, game, args)
+		case "bestof":
+			message = handleCommandBestOf(user, game, args)
+		case "minfps":
+			message = handleCommandMinimumFps(user, game, args)
 		case "start":
 			message = handleCommandStartMatch(user, game)
 		case "end":
@@ -113,6 +127,14 @@ func handleMultiplayerCommands(user *sessions.User, channel *chat.Channel, args
 			message = handleCommandPlayerWins(user, game, args)
 		case "referee":
 			message = handleCommandReferee(user, game, args)
+		case "spectate":
+			message = handleCommandSpectatorAccess(user, game, args)
+		case "spectatorchat":
+			message = handleCommandSpectatorChat(user, game)
+		case "setscore":
+			message = handleCommandSetScore(user, game, args)
+		case "removereferee":
+			message = handleCommandRemoveReferee(user, game, args)
 		case "clearreferee":
 			message = handleCommandClearReferee(user, game)
 		case "tournament":
@@ -125,6 +147,14 @@ func handleMultiplayerCommands(user *sessions.User, channel *chat.Channel, args
 			message = handleCommandAutoHost(user, game)
 		case "randmap":
 			message = handleCommandRandomMap(user, game)
+		case "pause":
+			message = handleCommandPause(user, game, true)
+		case "resume":
+			message = handleCommandPause(user, game, false)
+		case "mapvote":
+			message = handleCommandStartMapVote(user, game, args)
+		case "vote":
+			message = handleCommandVoteMap(user, game, args)
 		case "debug":
 			message = handleCommandDebug(user, game)
 		case "mods":
@@ -135,13 +165,17 @@ func handleMultiplayerCommands(user *sessions.User, channel *chat.Channel, args
 	return message
 }
 
-// Handles the command to kick a user
-func handleCommandKickPlayer(user *sessions.User, game *Game, args []string) string {
+// Handles the command to kick a user. If ban is set, the user is unable to rejoin the game.
+func handleCommandKickPlayer(user *sessions.User, game *Game, args []string, ban bool) string {
 	if !game.isUserHost(user) {
 		return ""
 	}
 
 	if len(args) < 3 {
+		if ban {
+			return "You must provide a username to ban."
+		}
+
 		return "You must provide a username to kick."
 	}
 
@@ -159,10 +193,79 @@ func handleCommandKickPlayer(user *sessions.User, game *Game, args []string) str
 		return "That user is not in the game."
 	}
 
-	game.KickPlayer(user, target.Info.Id)
+	if err := game.KickPlayer(user, target.Info.Id, ban); err != nil {
+		return fmt.Sprintf("Unable to kick player: %v.", err)
+	}
+
 	return ""
 }
 
+// Handles the command to unban a user from the game
+func handleCommandUnbanPlayer(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide a username to unban."
+	}
+
+	target, err := db.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[2], "_", " ")))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "That user does not exist."
+		}
+
+		log.Printf("Error retrieving user from the database - %v\n", err)
+		return "An error occurred while executing this command."
+	}
+
+	if !game.IsPlayerBanned(target.Id) {
+		return "That user is not banned from the game."
+	}
+
+	game.UnbanPlayer(user, target.Id)
+	return fmt.Sprintf("%v has been unbanned from the game.", target.Username)
+}
+
+// Handles the command to reserve/release a slot in the game for a user
+func handleCommandReserveSlot(user *sessions.User, game *Game, args []string, reserve bool) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide a username."
+	}
+
+	target, err := db.GetUserByUsername(strings.ToLower(strings.ReplaceAll(args[2], "_", " ")))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "That user does not exist."
+		}
+
+		log.Printf("Error retrieving user from the database - %v\n", err)
+		return "An error occurred while executing this command."
+	}
+
+	if !reserve {
+		if !game.IsSlotReserved(target.Id) {
+			return "That user does not have a reserved slot."
+		}
+
+		game.ReleaseSlot(user, target.Id)
+		return fmt.Sprintf("The slot reserved for %v has been released.", target.Username)
+	}
+
+	if err := game.ReserveSlot(user, target.Id); err != nil {
+		return fmt.Sprintf("Unable to reserve a slot: %v.", err)
+	}
+
+	return fmt.Sprintf("A slot has been reserved for %v.", target.Username)
+}
+
 // Handles the command to change the name of the multiplayer game.
 func handleCommandChangeName(user *sessions.User, game *Game, args []string) string {
 	if !game.isUserHost(user) {
@@ -193,11 +296,10 @@ func handleCommandChangeHost(user *sessions.User, game *Game, args []string) str
 		return "That user is not online."
 	}
 
-	if !game.isUserInGame(target) {
-		return "That user is not in the game."
+	if err := game.TransferHost(user, target.Info.Id); err != nil {
+		return fmt.Sprintf("Unable to transfer host: %v.", err)
 	}
 
-	game.SetHost(user, target.Info.Id)
 	return ""
 }
 
@@ -228,10 +330,96 @@ func handleCommandChangeMap(user *sessions.User, game *Game, args []string) stri
 		return "There was an error while retrieving the map."
 	}
 
+	if err := game.validateMapDifficulty(song.DifficultyRating); err != nil {
+		return fmt.Sprintf("Unable to change the map: %v.", err)
+	}
+
+	if err := game.validateMapGameMode(song.GameMode); err != nil {
+		return fmt.Sprintf("Unable to change the map: %v.", err)
+	}
+
 	game.changeMapFromDbSong(song)
 	return ""
 }
 
+// Handles the command to pause or resume the match in progress
+func handleCommandPause(user *sessions.User, game *Game, pause bool) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if pause {
+		if err := game.Pause(user); err != nil {
+			return fmt.Sprintf("Unable to pause the match: %v.", err)
+		}
+
+		return ""
+	}
+
+	if err := game.Resume(user); err != nil {
+		return fmt.Sprintf("Unable to resume the match: %v.", err)
+	}
+
+	return ""
+}
+
+// Handles the command to start a vote on the next map
+func handleCommandStartMapVote(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	seconds := 60
+
+	if len(args) >= 3 {
+		var err error
+
+		if seconds, err = strconv.Atoi(args[2]); err != nil {
+			return "You must provide a valid amount of seconds."
+		}
+	}
+
+	if err := game.StartMapVote(user, time.Duration(seconds)*time.Second); err != nil {
+		return fmt.Sprintf("Unable to start the map vote: %v.", err)
+	}
+
+	return ""
+}
+
+// Handles the command to propose and vote for a map in the live map vote
+func handleCommandVoteMap(user *sessions.User, game *Game, args []string) string {
+	if !game.IsMapVoteActive() {
+		return "There is no map vote in progress."
+	}
+
+	if len(args) < 3 {
+		return "You must provide a map id."
+	}
+
+	id, err := strconv.Atoi(args[2])
+
+	if err != nil {
+		return "You must provide a valid map id."
+	}
+
+	song, err := db.GetSongMapById(id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "That map doesn't exist."
+		}
+
+		log.Printf("Error getting map %v from the database - %v\n", id, err)
+		return "There was an error while retrieving the map."
+	}
+
+	if err := game.ProposeMap(user, getMapFromDbSong(song)); err != nil {
+		return fmt.Sprintf("Unable to vote for the map: %v.", err)
+	}
+
+	return ""
+}
+
 // Handles the command to enable/disable host rotation
 func handleCommandHostRotation(user *sessions.User, game *Game) string {
 	if !game.isUserHost(user) {
@@ -242,6 +430,26 @@ func handleCommandHostRotation(user *sessions.User, game *Game) string {
 	return ""
 }
 
+// Handles the command to toggle host auto-ready
+func handleCommandHostAutoReady(user *sessions.User, game *Game) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	game.SetHostAutoReady(user, !game.Data.IsHostAutoReady)
+	return ""
+}
+
+// Handles the command to toggle if spectators are able to chat
+func handleCommandSpectatorChat(user *sessions.User, game *Game) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	game.SetSpectatorsCanChat(user, !game.Data.SpectatorsCanChat)
+	return ""
+}
+
 // Handles the command to set the max player count
 func handleCommandMaxPlayers(user *sessions.User, game *Game, args []string) string {
 	if !game.isUserHost(user) {
@@ -262,6 +470,46 @@ func handleCommandMaxPlayers(user *sessions.User, game *Game, args []string) str
 	return ""
 }
 
+// Handles the command to set the amount of matches in the series
+func handleCommandBestOf(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide the amount of matches in the series, or 0 to disable it."
+	}
+
+	bestOf, err := strconv.Atoi(args[2])
+
+	if err != nil {
+		return "You must provide a valid number."
+	}
+
+	game.SetBestOf(user, bestOf)
+	return ""
+}
+
+// Handles the command to set the minimum frame rate needed to ready up
+func handleCommandMinimumFps(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide the minimum fps, or 0 to disable it."
+	}
+
+	fps, err := strconv.Atoi(args[2])
+
+	if err != nil {
+		return "You must provide a valid number."
+	}
+
+	game.SetMinimumFps(user, fps)
+	return ""
+}
+
 // Handles the command to start the match
 func handleCommandStartMatch(user *sessions.User, game *Game) string {
 	if !game.isUserHost(user) {
@@ -469,11 +717,11 @@ func handleCommandClearWins(user *sessions.User, game *Game) string {
 		return ""
 	}
 
-	for _, playerId := range game.Data.PlayerIds {
-		game.SetPlayerWinCount(playerId, 0)
+	if err := game.ResetWins(user); err != nil {
+		return fmt.Sprintf("Unable to reset wins: %v.", err)
 	}
 
-	return "All player win counts have been reset back to zero."
+	return ""
 }
 
 // Handles the command to set a specific player's win count
@@ -515,7 +763,7 @@ func handleCommandReferee(user *sessions.User, game *Game, args []string) string
 	}
 
 	if len(args) < 3 {
-		return "You must provide a user to give host to."
+		return "You must provide a user to make a referee."
 	}
 
 	target := getUserFromCommandArgs(args)
@@ -528,18 +776,47 @@ func handleCommandReferee(user *sessions.User, game *Game, args []string) string
 		return "That user is not in the game."
 	}
 
-	game.SetReferee(user, target.Info.Id)
-	return fmt.Sprintf("%v is now the referee of the game.", target.Info.Username)
+	if err := game.AddReferee(user, target.Info.Id); err != nil {
+		return fmt.Sprintf("Unable to add referee: %v.", err)
+	}
+
+	return fmt.Sprintf("%v is now a referee of the game.", target.Info.Username)
+}
+
+// Handles the command to remove a referee from the game.
+func handleCommandRemoveReferee(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide a referee to remove."
+	}
+
+	target := getUserFromCommandArgs(args)
+
+	if target == nil {
+		return "That user is not online."
+	}
+
+	if err := game.RemoveReferee(user, target.Info.Id); err != nil {
+		return fmt.Sprintf("Unable to remove referee: %v.", err)
+	}
+
+	return fmt.Sprintf("%v is no longer a referee of the game.", target.Info.Username)
 }
 
-// Handles the command to clear the referee of the game.
+// Handles the command to clear the referees of the game.
 func handleCommandClearReferee(user *sessions.User, game *Game) string {
 	if !game.isUserHost(user) {
 		return ""
 	}
 
-	game.SetReferee(user, -1)
-	return "The referee of the game has been cleared."
+	if err := game.ClearReferees(user); err != nil {
+		return fmt.Sprintf("Unable to clear referees: %v.", err)
+	}
+
+	return "The referees of the game have been cleared."
 }
 
 func handleCommandTournamentMode(user *sessions.User, game *Game) string {
@@ -571,10 +848,87 @@ func handleCommandInvite(user *sessions.User, game *Game, args []string) string
 		return "That user is already in the game."
 	}
 
-	game.SendInvite(user, target)
+	if err := game.SendInvite(user, target); err != nil {
+		return err.Error()
+	}
+
 	return ""
 }
 
+// Handles the command to change who is able to spectate the game
+func handleCommandSpectatorAccess(user *sessions.User, game *Game, args []string) string {
+	if !game.isUserHost(user) {
+		return ""
+	}
+
+	if len(args) < 3 {
+		return "You must provide the spectator access (password/invite/open)."
+	}
+
+	var access objects.MultiplayerGameSpectatorAccess
+
+	switch strings.ToLower(args[2]) {
+	case "password":
+		access = objects.MultiplayerGameSpectatorAccessPassword
+	case "invite":
+		access = objects.MultiplayerGameSpectatorAccessInvite
+	case "open":
+		access = objects.MultiplayerGameSpectatorAccessOpen
+	default:
+		return "You have provided an invalid spectator access (password/invite/open)."
+	}
+
+	game.SetSpectatorAccess(user, access)
+	return ""
+}
+
+// Handles the command for the referee to override a player's score
+func handleCommandSetScore(user *sessions.User, game *Game, args []string) string {
+	if !game.isReferee(user.Info.Id) {
+		return ""
+	}
+
+	if len(args) < 6 {
+		return "You must provide a username, performance rating, accuracy and max combo."
+	}
+
+	target := getUserFromCommandArgs(args)
+
+	if target == nil {
+		return "That player is not online."
+	}
+
+	performanceRating, err := strconv.ParseFloat(args[3], 64)
+
+	if err != nil {
+		return "You must provide a valid performance rating."
+	}
+
+	accuracy, err := strconv.ParseFloat(args[4], 64)
+
+	if err != nil || accuracy < 0 || accuracy > 100 {
+		return "You must provide a valid accuracy between 0 and 100."
+	}
+
+	maxCombo, err := strconv.Atoi(args[5])
+
+	if err != nil || maxCombo < 0 {
+		return "You must provide a valid max combo."
+	}
+
+	err = game.SetPlayerScore(user, target.Info.Id, ScoreOverride{
+		PerformanceRating: performanceRating,
+		Accuracy:          accuracy,
+		MaxCombo:          maxCombo,
+	})
+
+	if err != nil {
+		return fmt.Sprintf("Unable to override the score: %v.", err)
+	}
+
+	return fmt.Sprintf("%v's score has been overridden by the referee.", target.Info.Username)
+}
+
 // Handles the command to roll a random number between 0 and 100
 func handleCommandRoll(user *sessions.User) string {
 	rand.Seed(time.Now().UnixNano())
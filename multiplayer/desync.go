@@ -0,0 +1,53 @@
+package multiplayer
+
+import (
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"log"
+	"time"
+)
+
+const (
+	maxDesyncReports   = 3      // The amount of desync reports a user can send within the window
+	desyncReportWindow = 30_000 // The amount of milliseconds desync reports are counted over
+)
+
+// HandleDesyncReport Resends the authoritative match state to a user who reported that they are out of sync.
+// Users that report too often are ignored and logged, as it usually points to a client issue.
+func (game *Game) HandleDesyncReport(user *sessions.User) {
+	if !game.isUserInGame(user) && !utils.Includes(game.spectators, user.Info.Id) {
+		return
+	}
+
+	if !game.takeDesyncReport(user.Info.Id) {
+		log.Printf("[%v - %v] Ignored excessive desync reports in multiplayer game: %v\n",
+			user.Info.Username, user.Info.Id, game.Data.GameId)
+		return
+	}
+
+	game.SendMatchSettings(user)
+
+	sessions.SendPacketToUser(packets.NewServerGameMatchState(game.Data.InProgress, game.matchStartTime,
+		game.countdownEndTime, game.playersInMatch, game.playersFinished), user)
+}
+
+// Records a desync report from a user. Returns false if they have sent too many recently.
+func (game *Game) takeDesyncReport(userId int) bool {
+	now := time.Now().UnixMilli()
+	recent := make([]int64, 0, maxDesyncReports)
+
+	for _, t := range game.desyncReports[userId] {
+		if now-t < desyncReportWindow {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= maxDesyncReports {
+		game.desyncReports[userId] = recent
+		return false
+	}
+
+	game.desyncReports[userId] = append(recent, now)
+	return true
+}
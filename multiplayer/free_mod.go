@@ -0,0 +1,49 @@
+package multiplayer
+
+import (
+	"errors"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/objects"
+)
+
+// Returns an error if a player's modifiers aren't allowed by the free mod type. Players are only able to
+// differ from the global modifiers in the categories that free mod allows.
+func validatePlayerModifiers(freeMod objects.MultiplayerGameFreeMod, globalMods common.Mods, mods common.Mods) error {
+	if err := common.ValidateModifiers(mods); err != nil {
+		return err
+	}
+
+	var allowed common.Mods
+
+	if freeMod&objects.MultiplayerGameFreeModRegular != 0 {
+		allowed |= ^getAllSpeedMods()
+	}
+
+	if freeMod&objects.MultiplayerGameFreeModRate != 0 {
+		allowed |= getAllSpeedMods()
+	}
+
+	if (mods&^globalMods)&^allowed == 0 {
+		return nil
+	}
+
+	switch {
+	case freeMod == objects.MultiplayerGameFreeModNone:
+		return errors.New("free mod is not enabled")
+	case freeMod&objects.MultiplayerGameFreeModRate == 0:
+		return errors.New("free rate is not enabled")
+	default:
+		return errors.New("only the rate can be changed with free rate")
+	}
+}
+
+// Returns every speed modifier combined
+func getAllSpeedMods() common.Mods {
+	var mods common.Mods
+
+	for _, speedMod := range common.SpeedMods {
+		mods |= speedMod
+	}
+
+	return mods
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sync"
 	"time"
 
 	"example.com/Quaver/Z/chat"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
@@ -18,20 +20,49 @@ import (
 )
 
 type Game struct {
-	mutex               *utils.Mutex                    // Locks down the game to prevent race conditions
-	Data                *objects.MultiplayerGame        // Data about the multiplayer game that is sent in a packet
-	Password            string                          // The password for the game. This is different from Data.CreationPassword, as it is hidden from users.
-	CreatorId           int                             // The id of the user who created the game
-	countdownTimer      *time.Timer                     // Counts down before starting the game
-	playersInvited      []int                           // A list of users who have been invited to the game
-	playersInMatch      []int                           // A list of users who are currently playing the current match
-	playersScreenLoaded []int                           // A list of users whose screens have loaded in-game. The match doesn't start until all players are loaded.
-	playersFinished     []int                           // A list of users who have finished playing the map
-	playersSkipped      []int                           // A list of players who have skipped the map in multiplayer
-	playerScores        map[int]*scoring.ScoreProcessor // Score processors for players in the game
-	chatChannel         *chat.Channel                   // The multiplayer chat
-	spectators          []int                           // The players who are currently spectating the game
-	isDisbanded         bool                            // If the game has been disbanded
+	mutex                   *utils.Mutex                    // Locks down the game to prevent race conditions
+	Data                    *objects.MultiplayerGame        // Data about the multiplayer game that is sent in a packet
+	CreatorId               int                             // The id of the user who created the game
+	state                   GameState                       // The stage of the match the game is at
+	passwordSalt            []byte                          // The random salt the game's password is hashed with
+	passwordHash            []byte                          // The salted hash of the game's password. Empty if the game has no password.
+	countdownTimer          *time.Timer                     // Counts down before starting the game
+	countdownEndTime        int64                           // The time the live countdown will start the match at
+	readyCheckTimer         *time.Timer                     // Ends the ready check once players have run out of time to ready up
+	readyCheckEndTime       int64                           // The time the live ready check ends at
+	playersExcluded         []int                           // Players who failed the ready check and are left out of the upcoming match
+	mapVoteTimer            *time.Timer                     // Ends the map vote and changes to the winning map
+	mapVoteEndTime          int64                           // The time the live map vote ends at
+	mapVoteProposals        []*packets.ClientChangeGameMap  // The maps proposed in the live map vote, in the order they were proposed
+	mapVotes                map[int]string                  // The md5 of the map each player voted for, keyed by user id
+	refereeTimers           map[int]*time.Timer             // Releases the role of each referee who doesn't reconnect in time, keyed by user id
+	playersInvited          []int                           // A list of users who have been invited to the game
+	playersBanned           []int                           // A list of users who have been banned from joining the game
+	playersReserved         []int                           // A list of users who have a slot held for them in the game
+	inviteSenders           map[int]int                     // The id of the user who sent each outstanding invite, keyed by the invited user
+	playersInMatch          []int                           // A list of users who are currently playing the current match
+	playersScreenLoaded     []int                           // A list of users whose screens have loaded in-game. The match doesn't start until all players are loaded.
+	playersFinished         []int                           // A list of users who have finished playing the map
+	playersSkipped          []int                           // A list of players who have skipped the map in multiplayer
+	playerScores            map[int]*scoring.ScoreProcessor // Score processors for players in the game
+	scoreOverrides          []int                           // Players whose score has been manually overridden by the referee for the current match
+	lastTeamScores          TeamScores                      // The most recently computed team totals, kept for the match results
+	lastTeamScoresBroadcast int64                           // The last time the live team totals were broadcasted
+	lastScoreboardBroadcast int64                           // The last time the live scoreboard was broadcasted
+	matchStartTime          int64                           // The time the current match was started
+	pausedAt                int64                           // The time the current match was paused at. Zero if it isn't paused.
+	pausedById              int                             // The id of the user who paused the current match
+	pausedForReferee        bool                            // If the current match was paused by the server because a referee disconnected
+	desyncReports           map[int][]int64                 // Recent desync report times for each user, used to rate limit them
+	playerHealth            map[int]*playerHealth           // The health and lives of each player in a battle royale match
+	playersEliminated       []int                           // Players who have run out of lives in the current battle royale match
+	chatChannel             *chat.Channel                   // The multiplayer chat
+	chatPermissionsMutex    sync.Mutex                      // Guards readOnlyChatters, which the chat checks without locking the game
+	readOnlyChatters        map[int]bool                    // Spectators who aren't able to send messages to the game chat
+	spectators              []int                           // The players who are currently spectating the game
+	playerDownloadProgress  map[int]int                     // How far along players without the map are in downloading it, from 0 to 100
+	skipHostRotation        bool                            // If the host inherited their turn mid-match, so the rotation waits until after the next match
+	isDisbanded             bool                            // If the game has been disbanded
 }
 
 const (
@@ -41,22 +72,34 @@ const (
 // NewGame Creates a new multiplayer game from a game
 func NewGame(gameData *objects.MultiplayerGame, creatorId int) (*Game, error) {
 	game := Game{
-		mutex:               utils.NewMutex(),
-		Data:                gameData,
-		CreatorId:           creatorId,
-		Password:            gameData.CreationPassword,
-		playersInvited:      []int{},
-		playersInMatch:      []int{},
-		playersScreenLoaded: []int{},
-		playersFinished:     []int{},
-		playersSkipped:      []int{},
-		playerScores:        map[int]*scoring.ScoreProcessor{},
-		spectators:          []int{},
-	}
-
+		mutex:                  utils.NewMutex(),
+		Data:                   gameData,
+		CreatorId:              creatorId,
+		playersInvited:         []int{},
+		playersBanned:          []int{},
+		playersReserved:        []int{},
+		inviteSenders:          map[int]int{},
+		mapVotes:               map[int]string{},
+		playerDownloadProgress: map[int]int{},
+		refereeTimers:          map[int]*time.Timer{},
+		playersInMatch:         []int{},
+		playersScreenLoaded:    []int{},
+		playersFinished:        []int{},
+		playersSkipped:         []int{},
+		playerScores:           map[int]*scoring.ScoreProcessor{},
+		scoreOverrides:         []int{},
+		spectators:             []int{},
+		desyncReports:          map[int][]int64{},
+		playerHealth:           map[int]*playerHealth{},
+		playersEliminated:      []int{},
+	}
+
+	game.storePassword(gameData.CreationPassword)
 	game.Data.GameId = utils.GenerateRandomString(32)
 	game.Data.CreationPassword = ""
 	game.Data.SetDefaults()
+	game.Data.SpectatorsCanChat = !game.hasPassword()
+	game.applyDefaultModifiers()
 
 	var err error
 	game.Data.Id, err = db.InsertMultiplayerGame(game.Data.Name, game.Data.GameId)
@@ -69,6 +112,7 @@ func NewGame(gameData *objects.MultiplayerGame, creatorId int) (*Game, error) {
 	game.removeInactivePlayers()
 
 	game.chatChannel = chat.AddMultiplayerChannel(game.Data.GameId)
+	game.chatChannel.CanSend = game.canSendChatMessage
 	return &game, nil
 }
 
@@ -91,23 +135,44 @@ func (game *Game) AddPlayer(userId int, password string) {
 		currentGame.RemovePlayer(user.Info.Id)
 	}
 
-	if len(game.Data.PlayerIds) >= game.Data.MaxPlayers {
+	if utils.Includes(game.playersBanned, userId) {
+		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorBanned), user)
+		return
+	}
+
+	// Reserved slots are counted as taken, except for the user they're held for.
+	occupiedSlots := game.getOccupiedPlayerCount() + len(game.playersReserved)
+
+	if utils.Includes(game.playersReserved, userId) {
+		occupiedSlots--
+	}
+
+	if occupiedSlots >= game.Data.MaxPlayers {
 		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorFull), user)
 		return
 	}
 
 	// Check password in the event that the user wasn't invited or has a swan-bypass.
-	if (game.Data.HasPassword && game.Password != password) && !utils.Includes(game.playersInvited, userId) && !common.IsSwan(user.Info.UserGroups) {
+	if (game.Data.HasPassword && !game.CheckPassword(password)) && !utils.Includes(game.playersInvited, userId) && !common.IsSwan(user.Info.UserGroups) {
 		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorPassword), user)
 		return
 	}
 
 	if utils.Includes(game.playersInvited, userId) {
 		game.playersInvited = utils.Filter(game.playersInvited, func(x int) bool { return x != userId })
+
+		if sender := sessions.GetUserById(game.inviteSenders[userId]); sender != nil {
+			sender.RemoveOutstandingInvite(game.Data.Id, userId)
+		}
+
+		delete(game.inviteSenders, userId)
 	}
 
+	game.playersReserved = utils.Filter(game.playersReserved, func(x int) bool { return x != userId })
+
 	game.Data.PlayerIds = append(game.Data.PlayerIds, user.Info.Id)
 	game.Data.PlayerModifiers = append(game.Data.PlayerModifiers, &objects.MultiplayerGamePlayerMods{Id: user.Info.Id})
+	game.updateChatPermissions()
 
 	// Player wins persist even if a user leaves and joins the game pack at a later time
 	_, err := utils.Find(game.Data.PlayerWins, func(x *objects.MultiplayerGamePlayerWins) bool { return x.Id == user.Info.Id })
@@ -118,6 +183,7 @@ func (game *Game) AddPlayer(userId int, password string) {
 	user.SetMultiplayerGameId(game.Data.Id)
 	user.StopSpectatingAll()
 
+	team := game.assignTeam(user.Info.Id)
 	game.cachePlayer(user.Info.Id)
 	game.chatChannel.AddUser(user)
 
@@ -125,8 +191,8 @@ func (game *Game) AddPlayer(userId int, password string) {
 		game.SetHost(nil, user.Info.Id)
 	}
 
-	if game.Data.RefereeId == user.Info.Id {
-		game.SetReferee(nil, user.Info.Id)
+	if game.isReferee(user.Info.Id) {
+		game.restoreReferee(user)
 	}
 
 	RemoveUserFromLobby(user)
@@ -136,6 +202,7 @@ func (game *Game) AddPlayer(userId int, password string) {
 	sessions.SendPacketToUser(packets.NewServerMultiplayerGameInfo(game.Data), user)
 	sessions.SendPacketToUser(packets.NewServerJoinGame(game.Data.GameId), user)
 	game.sendPacketToPlayers(packets.NewServerUserJoinedGame(user.Info.Id))
+	game.sendPacketToPlayers(packets.NewServerGamePlayerTeamChanged(user.Info.Id, team))
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
@@ -143,7 +210,16 @@ func (game *Game) AddPlayer(userId int, password string) {
 func (game *Game) RemovePlayer(userId int) {
 	user := sessions.GetUserById(userId)
 
+	var playerIds = append([]int{}, game.Data.PlayerIds...)
 	var playerWasInMatch = utils.Includes(game.playersInMatch, userId)
+	var userWasSpectating = utils.Includes(game.spectators, userId)
+
+	// If the host leaves during host rotation, the player after them is next, rather than starting over.
+	var nextHostId = getNextRotationHost(game.Data.PlayerIds, userId)
+
+	delete(game.desyncReports, userId)
+	delete(game.mapVotes, userId)
+	delete(game.playerDownloadProgress, userId)
 
 	if user != nil {
 		user.SetMultiplayerGameId(0)
@@ -154,22 +230,47 @@ func (game *Game) RemovePlayer(userId int) {
 
 	game.Data.PlayerIds = utils.Filter(game.Data.PlayerIds, func(x int) bool { return x != userId })
 	game.Data.PlayerModifiers = utils.Filter(game.Data.PlayerModifiers, func(x *objects.MultiplayerGamePlayerMods) bool { return x.Id != userId })
+	game.Data.PlayersReady = utils.Filter(game.Data.PlayersReady, func(x int) bool { return x != userId })
+	game.Data.PlayersWithoutMap = utils.Filter(game.Data.PlayersWithoutMap, func(x int) bool { return x != userId })
+	game.removePlayerFromTeams(userId)
 	game.playersInMatch = utils.Filter(game.playersInMatch, func(x int) bool { return x != userId })
 	game.playersScreenLoaded = utils.Filter(game.playersScreenLoaded, func(x int) bool { return x != userId })
 	game.playersFinished = utils.Filter(game.playersFinished, func(x int) bool { return x != userId })
 	game.playersSkipped = utils.Filter(game.playersSkipped, func(x int) bool { return x != userId })
-	game.spectators = utils.Filter(game.spectators, func(x int) bool { return x != userId })
-	game.deleteCachedPlayer(userId)
+	game.setSpectators(utils.Filter(game.spectators, func(x int) bool { return x != userId }))
 	delete(game.playerScores, userId)
+	delete(game.playerHealth, userId)
 
-	// Disband game since there are no more players left
+	// Disband game since there are no more players left. The players who were
+	// still in the game are deleted from redis along with the rest of the game.
 	if len(game.Data.PlayerIds) == 0 {
-		game.disband()
+		game.disband(playerIds)
 		return
 	}
 
+	game.deleteCachedPlayer(userId)
+
+	if userWasSpectating {
+		game.cacheMatchSettings()
+	}
+
+	// Leaving a battle royale may leave only one player standing
+	if game.Data.InProgress && len(game.playerHealth) > 0 && len(game.getBattleRoyaleSurvivors()) <= 1 {
+		game.EndGame(false)
+	}
+
 	if game.Data.HostId == userId {
-		game.SetHost(nil, game.Data.PlayerIds[0])
+		if game.Data.IsHostRotation && utils.Includes(game.Data.PlayerIds, nextHostId) {
+			// The next host's turn is the upcoming match, so the one in progress doesn't count towards it
+			game.skipHostRotation = game.Data.InProgress
+			game.SetHost(nil, nextHostId)
+		} else {
+			game.SetHost(nil, game.Data.PlayerIds[0])
+		}
+	}
+
+	if game.isReferee(userId) {
+		game.holdRefereeRole(userId)
 	}
 
 	game.sendPacketToPlayers(packets.NewServerUserLeftGame(userId))
@@ -188,22 +289,116 @@ func (game *Game) RemovePlayer(userId int) {
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
-// KickPlayer Kicks a player from the multiplayer game
-func (game *Game) KickPlayer(requester *sessions.User, userId int) {
-	if !game.isUserHost(requester) || !utils.Includes(game.Data.PlayerIds, userId) {
-		return
+// KickPlayer Kicks a player from the multiplayer game. If ban is set, the player is unable to rejoin the game.
+func (game *Game) KickPlayer(requester *sessions.User, userId int, ban bool) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to kick players")
+	}
+
+	if requester != nil && requester.Info.Id == userId {
+		return errors.New("you cannot kick yourself from the game")
+	}
+
+	if !utils.Includes(game.Data.PlayerIds, userId) {
+		return errors.New("that user is not in the game")
+	}
+
+	if ban && !utils.Includes(game.playersBanned, userId) {
+		game.playersBanned = append(game.playersBanned, userId)
+		game.playersInvited = utils.Filter(game.playersInvited, func(x int) bool { return x != userId })
 	}
 
+	// Unlike leaving, being kicked doesn't keep the player's wins around for when they rejoin
+	game.Data.PlayerWins = utils.Filter(game.Data.PlayerWins, func(x *objects.MultiplayerGamePlayerWins) bool { return x.Id != userId })
 	game.RemovePlayer(userId)
 
 	user := sessions.GetUserById(userId)
 
 	if user == nil {
-		return
+		return nil
+	}
+
+	if ban {
+		game.sendBotMessage(fmt.Sprintf("%v has been banned from the game.", user.Info.Username))
+	} else {
+		game.sendBotMessage(fmt.Sprintf("%v has been kicked from the game.", user.Info.Username))
 	}
 
-	game.sendBotMessage(fmt.Sprintf("%v has been kicked from the game.", user.Info.Username))
 	sessions.SendPacketToUser(packets.NewServerGameKicked(), user)
+	return nil
+}
+
+// UnbanPlayer Allows a previously banned player to join the game again
+func (game *Game) UnbanPlayer(requester *sessions.User, userId int) {
+	if !game.isUserHost(requester) || !utils.Includes(game.playersBanned, userId) {
+		return
+	}
+
+	game.playersBanned = utils.Filter(game.playersBanned, func(x int) bool { return x != userId })
+}
+
+// TransferHost Hands host over to a chosen player. Only the host can do this, and the new host has to be
+// in the game and have the current map.
+func (game *Game) TransferHost(requester *sessions.User, newHostId int) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to transfer host")
+	}
+
+	if !utils.Includes(game.Data.PlayerIds, newHostId) {
+		return errors.New("that user is not in the game")
+	}
+
+	if newHostId == game.Data.HostId {
+		return errors.New("that user is already the host")
+	}
+
+	if utils.Includes(game.Data.PlayersWithoutMap, newHostId) {
+		return errors.New("that user doesn't have the current map yet")
+	}
+
+	game.SetHost(requester, newHostId)
+	return nil
+}
+
+// ReserveSlot Holds a slot in the game for a user, so other players can't take it
+func (game *Game) ReserveSlot(requester *sessions.User, userId int) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to reserve slots")
+	}
+
+	if utils.Includes(game.Data.PlayerIds, userId) {
+		return errors.New("that user is already in the game")
+	}
+
+	if utils.Includes(game.playersReserved, userId) {
+		return errors.New("that user already has a reserved slot")
+	}
+
+	if game.getOccupiedPlayerCount()+len(game.playersReserved) >= game.Data.MaxPlayers {
+		return errors.New("there are no free slots left to reserve")
+	}
+
+	game.playersReserved = append(game.playersReserved, userId)
+	return nil
+}
+
+// ReleaseSlot Releases a slot that was reserved for a user
+func (game *Game) ReleaseSlot(requester *sessions.User, userId int) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.playersReserved = utils.Filter(game.playersReserved, func(x int) bool { return x != userId })
+}
+
+// IsSlotReserved Returns if a slot is reserved for a user
+func (game *Game) IsSlotReserved(userId int) bool {
+	return utils.Includes(game.playersReserved, userId)
+}
+
+// IsPlayerBanned Returns if a player is banned from joining the game
+func (game *Game) IsPlayerBanned(userId int) bool {
+	return utils.Includes(game.playersBanned, userId)
 }
 
 // AddSpectator Adds a spectator to the game.
@@ -213,22 +408,36 @@ func (game *Game) AddSpectator(user *sessions.User, password string) {
 		return
 	}
 
-	if (game.Data.HasPassword && game.Password != password) && !common.IsSwan(user.Info.UserGroups) {
+	if !game.canSpectate(user, password) {
 		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorPassword), user)
 		return
 	}
 
+	if utils.Includes(game.playersBanned, user.Info.Id) {
+		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorBanned), user)
+		return
+	}
+
 	if utils.Includes(game.spectators, user.Info.Id) {
 		return
 	}
 
+	// The spectator's place is held from here on, so concurrent joins can't go over the server-wide cap together.
+	if !game.tryAddSpectator(user.Info.Id) {
+		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorTooManySpectators), user)
+		sessions.SendPacketToUser(packets.NewServerNotificationError("There are too many spectators on the server right now. Please try again later."), user)
+		return
+	}
+
 	currGame := GetGameById(user.GetMultiplayerGameId())
 
 	if currGame != nil && currGame != game {
 		currGame.RemovePlayer(user.Info.Id)
 	}
 
-	if len(game.playersInMatch) == 1 && game.Data.InProgress && user.Info.Id != game.Data.RefereeId {
+	if len(game.playersInMatch) == 1 && game.Data.InProgress && !game.isReferee(user.Info.Id) {
+		game.setSpectators(utils.Filter(game.spectators, func(x int) bool { return x != user.Info.Id }))
+
 		var player = sessions.GetUserById(game.playersInMatch[0])
 		player.AddSpectator(user)
 		sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorMatchNoExists), user)
@@ -236,7 +445,7 @@ func (game *Game) AddSpectator(user *sessions.User, password string) {
 		return
 	}
 
-	game.spectators = append(game.spectators, user.Info.Id)
+	game.cacheMatchSettings()
 	game.chatChannel.AddUser(user)
 	user.SetMultiplayerGameId(game.Data.Id)
 	RemoveUserFromLobby(user)
@@ -245,6 +454,10 @@ func (game *Game) AddSpectator(user *sessions.User, password string) {
 	sessions.SendPacketToUser(packets.NewServerSpectateMultiplayerGame(game.Data.GameId), user)
 	sendLobbyUsersGameInfoPacket(game, true)
 
+	if game.isReferee(user.Info.Id) {
+		game.restoreReferee(user)
+	}
+
 	if game.Data.InProgress {
 		if game.initializeSpectator(user) {
 			sessions.SendPacketToUser(packets.NewServerGameStart(), user)
@@ -269,11 +482,20 @@ func (game *Game) SetHost(requester *sessions.User, userId int) {
 		game.sendBotMessage(fmt.Sprintf("%v is now the host of the game.", user.Info.Username))
 	}
 
+	oldHostId := game.Data.HostId
+
 	game.Data.HostId = userId
 	game.SetHostSelectingMap(nil, false, false)
 	game.validateAndCacheSettings()
 
 	game.sendPacketToPlayers(packets.NewServerGameChangeHost(game.Data.HostId))
+
+	// Auto-ready follows the host, so the previous host has to ready up on their own again.
+	if game.Data.IsHostAutoReady && oldHostId != userId && utils.Includes(game.Data.PlayersReady, oldHostId) {
+		game.SetPlayerNotReady(oldHostId)
+	}
+
+	game.readyHostIfAutoReady()
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
@@ -287,6 +509,29 @@ func (game *Game) ChangeMap(requester *sessions.User, packet *packets.ClientChan
 		return
 	}
 
+	// Maps selected by the server come straight from the database, so only client selections need validation.
+	if requester != nil {
+		if err := validateMapSelection(packet); err != nil {
+			if err != errMapNotFound && err != errMapMd5Mismatch {
+				log.Printf("Error validating multiplayer map %v - %v\n", packet.MapId, err)
+				err = errors.New("there was an error while validating the selected map")
+			}
+
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to change the map: %v.", err)), requester)
+			return
+		}
+
+		if err := game.validateMapDifficulty(packet.DifficultyRating); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to change the map: %v.", err)), requester)
+			return
+		}
+
+		if err := game.validateMapGameMode(packet.Mode); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to change the map: %v.", err)), requester)
+			return
+		}
+	}
+
 	game.Data.MapMD5 = packet.MD5
 	game.Data.MapMD5Alternative = packet.AlternativeMD5
 	game.Data.MapId = packet.MapId
@@ -297,6 +542,7 @@ func (game *Game) ChangeMap(requester *sessions.User, packet *packets.ClientChan
 	game.Data.MapDifficultyRatingAll = packet.DifficultyRatingAll
 	game.Data.PlayersWithoutMap = []int{}
 	game.Data.PlayersReady = []int{}
+	game.playerDownloadProgress = map[int]int{}
 	game.clearReadyPlayers(false)
 	game.clearCountdown()
 	game.SetDonatorMapsetShared(false, false)
@@ -304,12 +550,14 @@ func (game *Game) ChangeMap(requester *sessions.User, packet *packets.ClientChan
 
 	game.sendBotMessage(fmt.Sprintf("The map has been changed to: %v.", game.Data.MapName))
 	game.sendPacketToPlayers(packets.NewServerGameMapChanged(packet))
+	game.readyHostIfAutoReady()
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
 // SetPlayerDoesntHaveMap Sets that a player does not have the map downloaded
 func (game *Game) SetPlayerDoesntHaveMap(userId int) {
 	game.Data.PlayersWithoutMap = append(game.Data.PlayersWithoutMap, userId)
+	game.playerDownloadProgress[userId] = 0
 	game.cachePlayer(userId)
 
 	game.sendPacketToPlayers(packets.NewServerGamePlayerNoMap(userId))
@@ -319,18 +567,56 @@ func (game *Game) SetPlayerDoesntHaveMap(userId int) {
 // SetPlayerHasMap Sets that a player now has the currently played map
 func (game *Game) SetPlayerHasMap(userId int) {
 	game.Data.PlayersWithoutMap = utils.Filter(game.Data.PlayersWithoutMap, func(x int) bool { return x != userId })
+	delete(game.playerDownloadProgress, userId)
 	game.cachePlayer(userId)
 
 	game.sendPacketToPlayers(packets.NewServerGamePlayerHasMap(userId))
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
+// SetPlayerDownloadProgress Sets how far along a player without the map is in downloading it.
+// Once the download is complete, the player has the map.
+func (game *Game) SetPlayerDownloadProgress(userId int, progress int) {
+	if !utils.Includes(game.Data.PlayersWithoutMap, userId) {
+		return
+	}
+
+	progress = utils.Clamp(progress, 0, 100)
+
+	if progress == 100 {
+		game.SetPlayerHasMap(userId)
+		return
+	}
+
+	if game.playerDownloadProgress[userId] == progress {
+		return
+	}
+
+	game.playerDownloadProgress[userId] = progress
+	game.cachePlayer(userId)
+
+	game.sendPacketToPlayers(packets.NewServerGameMapDownloadProgress(userId, progress))
+}
+
+// GetPlayerDownloadProgress Returns how much of the current map a player has downloaded, from 0 to 100
+func (game *Game) GetPlayerDownloadProgress(userId int) int {
+	if !utils.Includes(game.Data.PlayersWithoutMap, userId) {
+		return 100
+	}
+
+	return game.playerDownloadProgress[userId]
+}
+
 // SetPlayerReady Sets that a player is currently ready to play
 func (game *Game) SetPlayerReady(userId int) {
 	if game.Data.InProgress {
 		return
 	}
 
+	if !game.meetsMinimumFps(userId) {
+		return
+	}
+
 	if !utils.Includes(game.Data.PlayersReady, userId) {
 		game.Data.PlayersReady = append(game.Data.PlayersReady, userId)
 	}
@@ -339,6 +625,11 @@ func (game *Game) SetPlayerReady(userId int) {
 
 	game.sendPacketToPlayers(packets.NewServerGamePlayerReady(userId))
 	sendLobbyUsersGameInfoPacket(game, true)
+
+	// No need to wait out the ready check once everyone is ready
+	if game.readyCheckTimer != nil && len(game.getReadyCheckFailures()) == 0 {
+		game.finishReadyCheck()
+	}
 }
 
 // SetPlayerNotReady Sets that a player is not ready to play
@@ -360,6 +651,19 @@ func (game *Game) StartCountdown(requester *sessions.User) {
 		return
 	}
 
+	if timeout := getReadyCheckTimeout(); timeout > 0 && len(game.getReadyCheckFailures()) > 0 {
+		if err := game.BeginReadyCheck(requester, timeout); err != nil {
+			sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("Unable to start ready check: %v.", err)), requester)
+		}
+
+		return
+	}
+
+	if err := game.SetState(GameStateCountdown); err != nil {
+		return
+	}
+
+	game.countdownEndTime = time.Now().UnixMilli() + 5000
 	game.countdownTimer = time.AfterFunc(5*time.Second, func() {
 		game.RunLocked(func() {
 			game.StartGame()
@@ -394,7 +698,7 @@ func (game *Game) MoveToSingleplayerSpectate() {
 	var player = sessions.GetUserById(game.playersInMatch[0])
 	for _, spectatorId := range game.spectators {
 		var spectator = sessions.GetUserById(spectatorId)
-		if spectatorId == game.Data.RefereeId {
+		if game.isReferee(spectatorId) {
 			continue
 		}
 		sessions.SendPacketToUser(packets.NewServerNotificationInfo("Moving you to singleplayer spectate because there's only one player in the match!"), spectator)
@@ -407,16 +711,18 @@ func (game *Game) MoveToSingleplayerSpectate() {
 
 // StartGame Starts the multiplayer game
 func (game *Game) StartGame() {
-	if game.Data.InProgress {
+	if err := game.SetState(GameStateInProgress); err != nil {
 		return
 	}
 
-	game.Data.InProgress = true
+	game.matchStartTime = time.Now().UnixMilli()
 
 	game.playersInMatch = utils.Filter(game.Data.PlayerIds, func(x int) bool {
-		return x != game.Data.RefereeId && !utils.Includes(game.Data.PlayersWithoutMap, x)
+		return !game.isReferee(x) && !utils.Includes(game.Data.PlayersWithoutMap, x) && !utils.Includes(game.playersExcluded, x)
 	})
 
+	game.playersExcluded = []int{}
+
 	// Force clear replay frames from the server
 	for _, playerId := range game.playersInMatch {
 		user := sessions.GetUserById(playerId)
@@ -429,6 +735,8 @@ func (game *Game) StartGame() {
 
 	game.initializeSpectators()
 	game.createScoreProcessors()
+	game.createPlayerHealth()
+	game.lastTeamScores = TeamScores{}
 	game.clearCountdown()
 	game.clearReadyPlayers(false)
 	game.SetHostSelectingMap(nil, false, false)
@@ -441,22 +749,30 @@ func (game *Game) StartGame() {
 
 // EndGame Ends the multiplayer game
 func (game *Game) EndGame(force bool) {
-	if !game.Data.InProgress {
+	if err := game.SetState(GameStateResults); err != nil {
 		return
 	}
 
 	game.clearCountdown()
 	game.clearReadyPlayers(false)
+	game.broadcastTeamScores(true)
+	game.broadcastScoreboard(true)
 	game.updatePlayerWinCount()
+	game.checkSeriesCompletion()
 	game.insertMatchIntoDatabase()
 	game.rotateHost()
 
-	game.Data.InProgress = false
+	_ = game.SetState(GameStateWaiting)
+	game.matchStartTime = 0
+	game.clearPause()
 	game.playersInMatch = []int{}
 	game.playersScreenLoaded = []int{}
 	game.playersFinished = []int{}
 	game.playersSkipped = []int{}
 	game.playerScores = map[int]*scoring.ScoreProcessor{}
+	game.scoreOverrides = []int{}
+	game.playerHealth = map[int]*playerHealth{}
+	game.playersEliminated = []int{}
 
 	if game.Data.IsAutoHost {
 		game.selectAutohostMap()
@@ -466,6 +782,7 @@ func (game *Game) EndGame(force bool) {
 
 	game.sendBotMessage("The match has ended.")
 	game.sendPacketToPlayers(packets.NewServerGameEnded(force))
+	game.readyHostIfAutoReady()
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
@@ -515,7 +832,7 @@ func (game *Game) SetPassword(requester *sessions.User, password string) {
 		return
 	}
 
-	game.Password = password
+	game.storePassword(password)
 	game.validateAndCacheSettings()
 
 	sendLobbyUsersGameInfoPacket(game, true)
@@ -602,10 +919,11 @@ func (game *Game) SetFreeMod(requester *sessions.User, freeMod objects.Multiplay
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
-// SetPlayerModifiers Sets the player modifiers for an individual user
-func (game *Game) SetPlayerModifiers(userId int, mods common.Mods) {
+// SetPlayerModifiers Sets the player modifiers for an individual user. The modifiers have to be allowed by the
+// game's free mod type.
+func (game *Game) SetPlayerModifiers(userId int, mods common.Mods) error {
 	if game.Data.InProgress {
-		return
+		return errors.New("the match is in progress")
 	}
 
 	playerMods, err := utils.Find(game.Data.PlayerModifiers, func(x *objects.MultiplayerGamePlayerMods) bool {
@@ -614,7 +932,11 @@ func (game *Game) SetPlayerModifiers(userId int, mods common.Mods) {
 
 	if err != nil {
 		log.Printf("[MP #v] Error getting playermods for user: #%v - %v\n", userId, err)
-		return
+		return errors.New("you are not in the game")
+	}
+
+	if err := validatePlayerModifiers(game.Data.FreeModType, game.Data.GlobalModifiers, mods); err != nil {
+		return err
 	}
 
 	playerMods.Modifiers = mods
@@ -622,6 +944,7 @@ func (game *Game) SetPlayerModifiers(userId int, mods common.Mods) {
 
 	game.sendPacketToPlayers(packets.NewServerGameChangePlayerModifiers(userId, mods))
 	sendLobbyUsersGameInfoPacket(game, true)
+	return nil
 }
 
 // SetHostRotation Sets whether host rotation will be enabled for the game
@@ -660,7 +983,12 @@ func (game *Game) SetMaxPlayerCount(requester *sessions.User, count int) {
 	}
 
 	// Can't change max players if there are more players in the game than the requested count
-	if len(game.Data.PlayerIds) > count {
+	if game.getOccupiedPlayerCount() > count {
+		return
+	}
+
+	// Team games need an even player count, so both teams can be full
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetTeam && count%2 != 0 {
 		return
 	}
 
@@ -672,18 +1000,98 @@ func (game *Game) SetMaxPlayerCount(requester *sessions.User, count int) {
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
-// SendInvite Sends an invitation to a user in the multiplayer game
-func (game *Game) SendInvite(sender *sessions.User, user *sessions.User) {
-	if user == nil {
+// SetRuleset Switches the game between free-for-all and team play. Switching to team play rebalances the teams,
+// and either way the team win counts start over.
+func (game *Game) SetRuleset(requester *sessions.User, ruleset objects.MultiplayerGameRuleset) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	if ruleset == game.Data.Ruleset || ruleset < objects.MultiplayerGameRulesetFreeForAll || ruleset > objects.MultiplayerGameRulesetBattleRoyale {
+		return
+	}
+
+	if game.Data.InProgress {
+		notifyTeamChangeFailed(requester, "You cannot change the ruleset while the match is in progress.")
+		return
+	}
+
+	if ruleset == objects.MultiplayerGameRulesetTeam && game.Data.MaxPlayers%2 != 0 {
+		notifyTeamChangeFailed(requester, "Team games need an even max player count.")
 		return
 	}
 
+	game.Data.Ruleset = ruleset
+
+	if ruleset == objects.MultiplayerGameRulesetTeam {
+		game.balanceTeams()
+	}
+
+	game.ResetTeamWins()
+	game.validateAndCacheSettings()
+
+	switch ruleset {
+	case objects.MultiplayerGameRulesetTeam:
+		game.sendBotMessage("The game is now being played in teams.")
+	case objects.MultiplayerGameRulesetBattleRoyale:
+		game.sendBotMessage("The game is now a battle royale. The last player standing wins.")
+	default:
+		game.sendBotMessage("The game is now free-for-all.")
+	}
+
+	game.sendPacketToPlayers(packets.NewServerGameRulesetChanged(ruleset))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// SendInvite Sends an invitation to a user in the multiplayer game.
+// Returns an error if the sender has too many outstanding invites.
+func (game *Game) SendInvite(sender *sessions.User, user *sessions.User) error {
+	if user == nil {
+		return nil
+	}
+
+	if !game.isUserInGame(sender) {
+		return errors.New("you must be in the game to invite players")
+	}
+
+	if game.isUserInGame(user) {
+		return errors.New("that user is already in the game")
+	}
+
+	// The sender isn't told they're blocked, so the invite looks like it went through. Nothing is sent, so it
+	// doesn't take up one of the sender's outstanding invites either.
+	if user.IsBlocking(sender.Info.Id) {
+		game.sendBotMessage(fmt.Sprintf("%v has invited %v to the game.", sender.Info.Username, user.Info.Username))
+		return nil
+	}
+
+	maxInvites, window := getInviteLimit()
+
+	if !sender.AddOutstandingInvite(game.Data.Id, user.Info.Id, maxInvites, window) {
+		return fmt.Errorf("you have sent too many invites, please wait before inviting more players")
+	}
+
 	if !utils.Includes(game.playersInvited, user.Info.Id) {
 		game.playersInvited = append(game.playersInvited, user.Info.Id)
 	}
 
+	game.inviteSenders[user.Info.Id] = sender.Info.Id
+
 	game.sendBotMessage(fmt.Sprintf("%v has invited %v to the game.", sender.Info.Username, user.Info.Username))
 	sessions.SendPacketToUser(packets.NewServerGameInvite(game.Data.GameId, sender.Info.Username), user)
+	return nil
+}
+
+// Expires every pending invite to the game and frees up the senders' invite capacity
+func (game *Game) expireInvites() {
+	for userId, senderId := range game.inviteSenders {
+		if sender := sessions.GetUserById(senderId); sender != nil {
+			sender.RemoveOutstandingInvite(game.Data.Id, userId)
+		}
+	}
+
+	game.playersInvited = []int{}
+	game.inviteSenders = map[int]int{}
 }
 
 // SetPlayerWinCount Sets the win count for a given player
@@ -703,32 +1111,178 @@ func (game *Game) SetPlayerWinCount(userId int, wins int) {
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
-// SetReferee Sets the referee for the game. Set userId to -1 to clear.
-func (game *Game) SetReferee(requester *sessions.User, userId int) {
-	// If the referee stays unchanged, the referee is rejoining.
-	// We need to add the referee to game.spectators so game.initializeSpectators()
-	// 	could make the referee spectate as well.
-	if game.Data.InProgress && userId != game.Data.RefereeId {
-		return
+// AddReferee Adds a referee to the game. Referees are able to control the game like the host and spectate the match,
+// without taking up a player slot.
+func (game *Game) AddReferee(requester *sessions.User, userId int) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to add referees")
 	}
 
+	if game.Data.InProgress {
+		return errors.New("referees can't be changed while the match is in progress")
+	}
+
+	if game.isReferee(userId) {
+		return errors.New("that user is already a referee")
+	}
+
+	game.Data.RefereeIds = append(game.Data.RefereeIds, userId)
+
+	if !utils.Includes(game.spectators, userId) {
+		game.setSpectators(append(game.spectators, userId))
+	}
+
+	game.updateReferees()
+	return nil
+}
+
+// RemoveReferee Removes a referee from the game
+func (game *Game) RemoveReferee(requester *sessions.User, userId int) error {
 	if !game.isUserHost(requester) {
-		return
+		return errors.New("only the host is able to remove referees")
+	}
+
+	if game.Data.InProgress {
+		return errors.New("referees can't be changed while the match is in progress")
+	}
+
+	if !game.isReferee(userId) {
+		return errors.New("that user is not a referee")
 	}
 
-	oldReferee := game.Data.RefereeId
-	game.Data.RefereeId = userId
+	game.releaseReferee(userId)
+	game.updateReferees()
+	return nil
+}
+
+// ClearReferees Removes every referee from the game
+func (game *Game) ClearReferees(requester *sessions.User) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to remove referees")
+	}
+
+	if game.Data.InProgress {
+		return errors.New("referees can't be changed while the match is in progress")
+	}
+
+	for _, id := range game.Data.RefereeIds {
+		game.releaseReferee(id)
+	}
+
+	game.updateReferees()
+	return nil
+}
+
+// Takes the referee role away from a user and stops them from spectating as a referee
+func (game *Game) releaseReferee(userId int) {
+	if timer, ok := game.refereeTimers[userId]; ok {
+		timer.Stop()
+		delete(game.refereeTimers, userId)
+	}
+
+	game.Data.RefereeIds = utils.Filter(game.Data.RefereeIds, func(x int) bool { return x != userId })
+	game.setSpectators(utils.Filter(game.spectators, func(x int) bool { return x != userId }))
+}
 
-	game.spectators = utils.Filter(game.spectators, func(x int) bool { return x != oldReferee })
+// Syncs the single referee older clients display with the referee list, then caches and broadcasts it
+func (game *Game) updateReferees() {
+	game.Data.RefereeId = -1
 
-	if game.Data.RefereeId != -1 && !utils.Includes(game.spectators, userId) {
-		game.spectators = append(game.spectators, userId)
+	if len(game.Data.RefereeIds) > 0 {
+		game.Data.RefereeId = game.Data.RefereeIds[0]
 	}
 
+	game.updateChatPermissions()
+	game.cacheMatchSettings()
+
 	game.sendPacketToPlayers(packets.NewServerGameSetReferee(game.Data.RefereeId))
 	sendLobbyUsersGameInfoPacket(game, true)
 }
 
+// Gives a referee who rejoined the game their role back, so they spectate the match again.
+func (game *Game) restoreReferee(user *sessions.User) {
+	if timer, ok := game.refereeTimers[user.Info.Id]; ok {
+		timer.Stop()
+		delete(game.refereeTimers, user.Info.Id)
+		game.sendBotMessage(fmt.Sprintf("The referee %v has reconnected.", user.Info.Username))
+		game.resumeAfterRefereeReturns()
+	}
+
+	if !utils.Includes(game.spectators, user.Info.Id) {
+		game.setSpectators(append(game.spectators, user.Info.Id))
+	}
+
+	game.updateReferees()
+}
+
+// Keeps the referee role reserved for a referee who just left, and releases it if they don't come back in time.
+func (game *Game) holdRefereeRole(refereeId int) {
+	gracePeriod, endMatch := getRefereeTimeoutSettings()
+
+	if timer, ok := game.refereeTimers[refereeId]; ok {
+		timer.Stop()
+	}
+
+	if game.refereeTimers == nil {
+		game.refereeTimers = map[int]*time.Timer{}
+	}
+
+	game.sendBotMessage(fmt.Sprintf("A referee has left the game. Their role will be held for %v seconds.", gracePeriod.Seconds()))
+	game.notifyOtherReferees(refereeId, fmt.Sprintf("A referee has disconnected from %v.", game.Data.Name))
+
+	if shouldPauseOnRefereeDisconnect() && game.state == GameStateInProgress && !game.IsPaused() {
+		if err := game.Pause(nil); err == nil {
+			game.pausedForReferee = true
+		}
+	}
+
+	game.refereeTimers[refereeId] = time.AfterFunc(gracePeriod, func() {
+		game.RunLocked(func() {
+			// The referee may have come back as either a player or a spectator
+			if game.isDisbanded || !game.isReferee(refereeId) ||
+				utils.Includes(game.Data.PlayerIds, refereeId) || utils.Includes(game.spectators, refereeId) {
+				return
+			}
+
+			game.releaseReferee(refereeId)
+
+			game.sendBotMessage("The referee did not reconnect in time, so their referee role has been released.")
+			game.updateReferees()
+			game.resumeAfterRefereeReturns()
+
+			if endMatch {
+				game.EndGame(true)
+			}
+
+			game.validateAndCacheSettings()
+		})
+	})
+}
+
+// Resumes the match if it was paused because a referee disconnected, once no other referee's role is still being held
+func (game *Game) resumeAfterRefereeReturns() {
+	if !game.pausedForReferee || len(game.refereeTimers) > 0 {
+		return
+	}
+
+	if err := game.Resume(nil); err != nil {
+		game.pausedForReferee = false
+	}
+}
+
+// Sends a notification to every referee other than the given one
+func (game *Game) notifyOtherReferees(refereeId int, message string) {
+	for _, id := range game.Data.RefereeIds {
+		if id == refereeId {
+			continue
+		}
+
+		for _, user := range sessions.GetUserSessions(id) {
+			sessions.SendPacketToUser(packets.NewServerNotificationInfo(message), user)
+		}
+	}
+}
+
 // SetPlayerScreenLoaded Handles when a client states that their gameplay screen has loaded at the start of a match
 func (game *Game) SetPlayerScreenLoaded(userId int) {
 	if !game.Data.InProgress || !utils.Includes(game.playersInMatch, userId) {
@@ -776,9 +1330,19 @@ func (game *Game) HandlePlayerJudgements(userId int, judgements []common.Judgeme
 		return
 	}
 
-	if score, ok := game.playerScores[userId]; ok {
+	game.updatePlayerHealth(userId, judgements)
+
+	// The match may have ended with the player's elimination
+	if !game.Data.InProgress {
+		return
+	}
+
+	// Overridden scores are final, so further judgements shouldn't change them.
+	if score, ok := game.playerScores[userId]; ok && !utils.Includes(game.scoreOverrides, userId) {
 		score.AddJudgements(judgements)
 		game.cachePlayerScore(userId, score)
+		game.broadcastTeamScores(false)
+		game.broadcastScoreboard(false)
 	}
 
 	packet := packets.NewServerGameJudgements(userId, judgements)
@@ -798,6 +1362,64 @@ func (game *Game) HandlePlayerJudgements(userId int, judgements []common.Judgeme
 	}
 }
 
+// SendMatchSettings Sends a user the current game settings and players, so their view of the game can resync.
+// Only works for users who are playing in or spectating the game.
+func (game *Game) SendMatchSettings(user *sessions.User) {
+	if !game.isUserInGame(user) && !utils.Includes(game.spectators, user.Info.Id) {
+		return
+	}
+
+	sessions.SendPacketToUser(packets.NewServerMultiplayerGameInfo(game.Data), user)
+	sessions.SendPacketToUser(packets.NewServerUserInfo(game.GetSerializedPlayers()), user)
+}
+
+// SetSpectatorAccess Sets who is able to spectate the game when it has a password
+func (game *Game) SetSpectatorAccess(requester *sessions.User, access objects.MultiplayerGameSpectatorAccess) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.Data.SpectatorAccess = access
+	game.validateAndCacheSettings()
+
+	game.sendBotMessage(fmt.Sprintf("Spectator access has been changed to: %v.", getSpectatorAccessName(game.Data.SpectatorAccess)))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// SetPlayerScore Overrides the score of a player in the current match. Only the referee is able to do this,
+// and only before the match has ended.
+func (game *Game) SetPlayerScore(requester *sessions.User, userId int, override ScoreOverride) error {
+	if requester == nil || !game.isReferee(requester.Info.Id) {
+		return errors.New("only referees are able to override scores")
+	}
+
+	if !game.Data.InProgress {
+		return errors.New("scores can only be overridden while the match is in progress")
+	}
+
+	score, ok := game.playerScores[userId]
+
+	if !ok {
+		return errors.New("that player does not have a score in the current match")
+	}
+
+	log.Printf("[MP #%v] Referee #%v overrode #%v's score (pr: %v -> %v, acc: %v -> %v, combo: %v -> %v)\n",
+		game.Data.Id, requester.Info.Id, userId, score.PerformanceRating, override.PerformanceRating,
+		score.Accuracy, override.Accuracy, score.MaxCombo, override.MaxCombo)
+
+	score.PerformanceRating = override.PerformanceRating
+	score.Accuracy = override.Accuracy
+	score.MaxCombo = override.MaxCombo
+
+	if !utils.Includes(game.scoreOverrides, userId) {
+		game.scoreOverrides = append(game.scoreOverrides, userId)
+	}
+
+	game.cachePlayerScore(userId, score)
+	game.broadcastTeamScores(true)
+	return nil
+}
+
 // SetTournamentMode Enables/disables tournament mode for the match
 func (game *Game) SetTournamentMode(requester *sessions.User, enabled bool) {
 	if !game.isUserHost(requester) {
@@ -868,9 +1490,170 @@ func (game *Game) SetAutoHost(requester *sessions.User, enabled bool) {
 	game.sendBotMessage(fmt.Sprintf("Auto Host has been disabled."))
 }
 
+// SetHostAutoReady Sets whether the host is automatically readied up
+func (game *Game) SetHostAutoReady(requester *sessions.User, enabled bool) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.Data.IsHostAutoReady = enabled
+	game.validateAndCacheSettings()
+	game.readyHostIfAutoReady()
+
+	game.sendBotMessage(fmt.Sprintf("Host auto-ready has been %v.", utils.BoolToEnabledString(game.Data.IsHostAutoReady)))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// ResetWins Resets the win counts of every player and team. The host and referee are able to do this,
+// but not during a match.
+func (game *Game) ResetWins(requester *sessions.User) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host or referee is able to reset wins")
+	}
+
+	if game.Data.InProgress {
+		return errors.New("wins cannot be reset while the match is in progress")
+	}
+
+	game.resetWins()
+	game.validateAndCacheSettings()
+	game.sendBotMessage("All win counts have been reset back to zero.")
+	sendLobbyUsersGameInfoPacket(game, true)
+	return nil
+}
+
+// Zeroes the win counts of every player and team
+func (game *Game) resetWins() {
+	game.ResetTeamWins()
+
+	for _, playerWins := range game.Data.PlayerWins {
+		game.SetPlayerWinCount(playerWins.Id, 0)
+	}
+}
+
+// SetSpectatorsCanChat Sets if spectators are able to send messages to the game chat
+func (game *Game) SetSpectatorsCanChat(requester *sessions.User, enabled bool) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.Data.SpectatorsCanChat = enabled
+	game.updateChatPermissions()
+	game.validateAndCacheSettings()
+
+	game.sendBotMessage(fmt.Sprintf("Spectator chat has been %v.", utils.BoolToEnabledString(game.Data.SpectatorsCanChat)))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// Returns if a user is able to send messages to the game chat. Spectators are read-only unless spectator chat is enabled.
+// The chat calls this without locking the game, so it only reads the permissions kept by updateChatPermissions.
+func (game *Game) canSendChatMessage(user *sessions.User) bool {
+	game.chatPermissionsMutex.Lock()
+	defer game.chatPermissionsMutex.Unlock()
+
+	return !game.readOnlyChatters[user.Info.Id]
+}
+
+// Rebuilds the list of spectators who aren't able to chat. Must be called whenever the players, spectators, referees
+// or spectator chat setting change.
+func (game *Game) updateChatPermissions() {
+	readOnly := map[int]bool{}
+
+	if !game.Data.SpectatorsCanChat {
+		for _, id := range game.spectators {
+			if !game.isReferee(id) && !utils.Includes(game.Data.PlayerIds, id) {
+				readOnly[id] = true
+			}
+		}
+	}
+
+	game.chatPermissionsMutex.Lock()
+	game.readOnlyChatters = readOnly
+	game.chatPermissionsMutex.Unlock()
+}
+
+// SetMinimumFps Sets the minimum reported frame rate players need in order to ready up. Set to zero to disable.
+func (game *Game) SetMinimumFps(requester *sessions.User, fps int) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.Data.MinimumFps = fps
+	game.validateAndCacheSettings()
+
+	if game.Data.MinimumFps == 0 {
+		game.sendBotMessage("The minimum frame rate requirement has been disabled.")
+	} else {
+		game.sendBotMessage(fmt.Sprintf("Players now need at least %v fps in order to ready up.", game.Data.MinimumFps))
+	}
+
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// Returns if a player's reported frame rate meets the game's minimum. Reports are client-provided, so this is
+// only advisory: players who haven't reported a frame rate are let through, and violations are logged.
+func (game *Game) meetsMinimumFps(userId int) bool {
+	if game.Data.MinimumFps <= 0 {
+		return true
+	}
+
+	user := sessions.GetUserById(userId)
+
+	if user == nil {
+		return true
+	}
+
+	fps := user.GetReportedFps()
+
+	if fps == 0 {
+		log.Printf("[MP #%v] %v (#%v) readied up without reporting their frame rate\n", game.Data.Id, user.Info.Username, user.Info.Id)
+		return true
+	}
+
+	if fps >= float64(game.Data.MinimumFps) {
+		return true
+	}
+
+	log.Printf("[MP #%v] %v (#%v) tried to ready up below the minimum frame rate (%.0f < %v)\n",
+		game.Data.Id, user.Info.Username, user.Info.Id, fps, game.Data.MinimumFps)
+
+	sessions.SendPacketToUser(packets.NewServerNotificationError(fmt.Sprintf("This game requires at least %v fps to ready up. "+
+		"Your client last reported %.0f fps.", game.Data.MinimumFps, fps)), user)
+	sessions.SendPacketToUser(packets.NewServerGamePlayerNotReady(userId), user)
+
+	return false
+}
+
+// SetBestOf Sets the amount of matches in the series (best-of-N). Set to zero to disable.
+// The host and referee are able to do this.
+func (game *Game) SetBestOf(requester *sessions.User, bestOf int) {
+	if !game.isUserHost(requester) {
+		return
+	}
+
+	game.Data.BestOf = bestOf
+	game.validateAndCacheSettings()
+
+	if game.Data.BestOf == 0 {
+		game.sendBotMessage("The match series has been disabled.")
+	} else {
+		game.sendBotMessage(fmt.Sprintf("The match series has been set to best of %v.", game.Data.BestOf))
+	}
+
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// GetSpectatorCount Returns the amount of users spectating the game. The game must be locked by the caller.
+func (game *Game) GetSpectatorCount() int {
+	return len(game.spectators)
+}
+
 // rotateHost Rotates the host to the next person in line.
 func (game *Game) rotateHost() {
-	if !game.Data.IsHostRotation {
+	skip := game.skipHostRotation
+	game.skipHostRotation = false
+
+	if !game.Data.IsHostRotation || skip {
 		return
 	}
 
@@ -878,48 +1661,102 @@ func (game *Game) rotateHost() {
 		return
 	}
 
-	index := utils.FindIndex(game.Data.PlayerIds, game.Data.HostId)
+	game.SetHost(nil, getNextRotationHost(game.Data.PlayerIds, game.Data.HostId))
+}
 
-	if index == -1 {
-		return
+// Returns the player that is next in line to be host after the given host. The current host acts as the
+// rotation cursor, so a manually transferred host continues the rotation from their own position.
+func getNextRotationHost(playerIds []int, hostId int) int {
+	if len(playerIds) == 0 {
+		return -1
 	}
 
-	// Cyclically rotates the host
-	if index+1 < len(game.Data.PlayerIds) {
-		game.SetHost(nil, game.Data.PlayerIds[index+1])
-	} else {
-		game.SetHost(nil, game.Data.PlayerIds[0])
+	index := utils.FindIndex(playerIds, hostId)
+
+	if index == -1 {
+		return playerIds[0]
 	}
+
+	return playerIds[(index+1)%len(playerIds)]
 }
 
-// Handles disbandment of the multiplayer game
-func (game *Game) disband() {
+// Handles disbandment of the multiplayer game. playerIds are the players who were in the game before the last one left.
+func (game *Game) disband(playerIds []int) {
 	game.EndGame(true)
 
 	// Tournament mode games are kept around and deleted manually
 	if game.Data.IsTournamentMode {
+		for _, id := range playerIds {
+			game.deleteCachedPlayer(id)
+		}
+
 		return
 	}
 
 	game.isDisbanded = true
-	game.deleteCachedMatchSettings()
+	game.playersBanned = []int{}
+	game.playersReserved = []int{}
+	game.setSpectators([]int{})
+
+	for id, timer := range game.refereeTimers {
+		timer.Stop()
+		delete(game.refereeTimers, id)
+	}
+
+	game.clearReadyCheck()
+	game.clearMapVote()
+	game.expireInvites()
+	game.deleteCachedGame(playerIds)
 	chat.RemoveMultiplayerChannel(game.Data.GameId)
 	RemoveGameFromLobby(game)
 }
 
-// Returns if the user is host of the game or has permission.
+// Returns if the user is host of the game or has permission. Referees are able to control the game like the host.
 func (game *Game) isUserHost(user *sessions.User) bool {
 	if user == nil {
 		return true
 	}
 
-	if user.Info.Id != game.Data.HostId && user.Info.Id != game.CreatorId && !common.HasUserGroup(user.Info.UserGroups, common.UserGroupDeveloper) {
+	if user.Info.Id != game.Data.HostId && user.Info.Id != game.CreatorId && !game.isReferee(user.Info.Id) &&
+		!common.HasUserGroup(user.Info.UserGroups, common.UserGroupDeveloper) {
 		return false
 	}
 
 	return true
 }
 
+// Returns the amount of players taking up slots in the game. Referees don't take up a slot.
+func (game *Game) getOccupiedPlayerCount() int {
+	count := 0
+
+	for _, id := range game.Data.PlayerIds {
+		if !game.isReferee(id) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Returns if a user is allowed to spectate the game with the given password.
+// Public games can always be spectated, and swans are able to bypass the check.
+func (game *Game) canSpectate(user *sessions.User, password string) bool {
+	if !game.Data.HasPassword || common.IsSwan(user.Info.UserGroups) || game.isReferee(user.Info.Id) {
+		return true
+	}
+
+	invited := utils.Includes(game.playersInvited, user.Info.Id)
+
+	switch game.Data.SpectatorAccess {
+	case objects.MultiplayerGameSpectatorAccessOpen:
+		return true
+	case objects.MultiplayerGameSpectatorAccessInvite:
+		return invited
+	default:
+		return invited || game.CheckPassword(password)
+	}
+}
+
 // Returns if a user is inside the game
 func (game *Game) isUserInGame(user *sessions.User) bool {
 	if user == nil {
@@ -932,17 +1769,30 @@ func (game *Game) isUserInGame(user *sessions.User) bool {
 // Clears all players that are ready.
 func (game *Game) clearReadyPlayers(sendToLobby bool) {
 	for _, id := range game.Data.PlayersReady {
-		game.cachePlayer(id)
 		game.sendPacketToPlayers(packets.NewServerGamePlayerNotReady(id))
 	}
 
 	game.Data.PlayersReady = []int{}
+	game.cacheAllPlayers()
 
 	if sendToLobby {
 		sendLobbyUsersGameInfoPacket(game, true)
 	}
 }
 
+// Readies up the host if host auto-ready is enabled and the host has the map
+func (game *Game) readyHostIfAutoReady() {
+	if !game.Data.IsHostAutoReady || game.Data.InProgress || game.Data.HostId == 0 {
+		return
+	}
+
+	if utils.Includes(game.Data.PlayersReady, game.Data.HostId) || utils.Includes(game.Data.PlayersWithoutMap, game.Data.HostId) {
+		return
+	}
+
+	game.SetPlayerReady(game.Data.HostId)
+}
+
 // Makes  the spectators start spectating playersInMatch.
 func (game *Game) initializeSpectators() {
 	for _, userId := range game.spectators {
@@ -1008,6 +1858,25 @@ func (game *Game) checkPlayerWinResult(userId int) (WinResult, error) {
 
 // Updates the win count for each player
 func (game *Game) updatePlayerWinCount() {
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetTeam && len(game.playerScores) > 0 {
+		if team, ok := getTeamMatchWinner(game.lastTeamScores); ok {
+			game.addTeamWin(team)
+			game.cacheMatchSettings()
+			game.sendPacketToPlayers(packets.NewServerGameTeamWinCount(game.Data.TeamRedWins, game.Data.TeamBlueWins))
+		}
+	}
+
+	// In battle royale, the last player standing wins regardless of score
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetBattleRoyale && len(game.playerHealth) > 0 {
+		for _, userId := range game.getBattleRoyaleSurvivors() {
+			if playerWins, err := utils.Find(game.Data.PlayerWins, func(x *objects.MultiplayerGamePlayerWins) bool { return x.Id == userId }); err == nil {
+				game.SetPlayerWinCount(userId, playerWins.Wins+1)
+			}
+		}
+
+		return
+	}
+
 	for userId := range game.playerScores {
 		winResult, err := game.checkPlayerWinResult(userId)
 
@@ -1084,9 +1953,16 @@ func (game *Game) insertMatchIntoDatabase() {
 
 // Clears and stops the countdown timer.
 func (game *Game) clearCountdown() {
+	game.clearReadyCheck()
+
 	if game.countdownTimer != nil {
 		game.countdownTimer.Stop()
 		game.countdownTimer = nil
+		game.countdownEndTime = 0
+	}
+
+	if game.state == GameStateCountdown {
+		_ = game.SetState(GameStateWaiting)
 	}
 
 	game.sendPacketToPlayers(packets.NewServerGameStopCountdown())
@@ -1135,8 +2011,13 @@ func (game *Game) isAllPlayersFinished() bool {
 	return true
 }
 
+// Returns if the user is one of the referees of the game
+func (game *Game) isReferee(userId int) bool {
+	return utils.Includes(game.Data.RefereeIds, userId)
+}
+
 func (game *Game) isPlayerSpectatorOrReferee(userId int) bool {
-	return game.Data.RefereeId == userId || utils.Includes(game.spectators, userId)
+	return game.isReferee(userId) || utils.Includes(game.spectators, userId)
 }
 
 // Checks if all the players in the game have skipped the map and sends a packet letting them know.
@@ -1167,7 +2048,12 @@ func (game *Game) selectAutohostMap() {
 }
 
 func (game *Game) changeMapFromDbSong(song *db.SongMap) {
-	game.ChangeMap(nil, &packets.ClientChangeGameMap{
+	game.ChangeMap(nil, getMapFromDbSong(song))
+}
+
+// Returns a map selection for a map in the database
+func getMapFromDbSong(song *db.SongMap) *packets.ClientChangeGameMap {
+	return &packets.ClientChangeGameMap{
 		MD5:                 song.Md5.String,
 		AlternativeMD5:      song.AlternativeMd5.String,
 		MapId:               song.Id,
@@ -1176,7 +2062,102 @@ func (game *Game) changeMapFromDbSong(song *db.SongMap) {
 		Mode:                song.GameMode,
 		DifficultyRating:    song.DifficultyRating,
 		DifficultyRatingAll: []float64{},
-	})
+	}
+}
+
+// Returns an error if a map's difficulty rating is outside the game's difficulty range.
+// A maximum of zero leaves the range unbounded.
+func (game *Game) validateMapDifficulty(difficultyRating float64) error {
+	min, max := float64(game.Data.FilterMinDifficultyRating), float64(game.Data.FilterMaxDifficultyRating)
+
+	if max <= 0 {
+		if difficultyRating < min {
+			return fmt.Errorf("the map's difficulty must be at least %v", game.Data.FilterMinDifficultyRating)
+		}
+
+		return nil
+	}
+
+	if difficultyRating < min || difficultyRating > max {
+		return fmt.Errorf("the map's difficulty must be between %v and %v", game.Data.FilterMinDifficultyRating, game.Data.FilterMaxDifficultyRating)
+	}
+
+	return nil
+}
+
+// Returns an error if a map's game mode isn't allowed in the game. Every mode is allowed if none are set.
+func (game *Game) validateMapGameMode(mode common.Mode) error {
+	if len(game.Data.FilterAllowedGameModes) == 0 || utils.Includes(game.Data.FilterAllowedGameModes, mode) {
+		return nil
+	}
+
+	return errors.New("the map's game mode isn't allowed in this game")
+}
+
+// Applies the configured default modifiers for the map's game mode if the host didn't select any
+func (game *Game) applyDefaultModifiers() {
+	if config.Instance == nil || game.Data.GlobalModifiers != 0 {
+		return
+	}
+
+	modeStr, err := common.GetModeString(game.Data.MapGameMode)
+
+	if err != nil {
+		return
+	}
+
+	modStr, ok := config.Instance.Multiplayer.DefaultModifiers[modeStr]
+
+	if !ok || modStr == "" {
+		return
+	}
+
+	mods, err := common.ParseModString(modStr)
+
+	if err != nil {
+		log.Printf("Invalid default multiplayer modifiers for %v - %v\n", modeStr, err)
+		return
+	}
+
+	game.Data.GlobalModifiers = mods
+	game.Data.MapDifficultyRating = game.findMapDifficultyRatingFromMods(mods)
+}
+
+// Returns how long a referee's role is held after they leave, and if the match should end once it is released
+func getRefereeTimeoutSettings() (time.Duration, bool) {
+	if config.Instance == nil {
+		return 60 * time.Second, false
+	}
+
+	gracePeriod := 60
+
+	if config.Instance.Multiplayer.RefereeGracePeriod > 0 {
+		gracePeriod = config.Instance.Multiplayer.RefereeGracePeriod
+	}
+
+	return time.Duration(gracePeriod) * time.Second, config.Instance.Multiplayer.RefereeTimeoutAction == "end"
+}
+
+// Returns if the match in progress should be paused while a disconnected referee's role is held
+func shouldPauseOnRefereeDisconnect() bool {
+	return config.Instance != nil && config.Instance.Multiplayer.RefereeDisconnectPause
+}
+
+// Returns the maximum amount of outstanding invites a user can have and the window they expire in
+func getInviteLimit() (int, time.Duration) {
+	maxInvites, window := 10, 60
+
+	if config.Instance != nil {
+		if config.Instance.Multiplayer.MaxOutstandingInvites > 0 {
+			maxInvites = config.Instance.Multiplayer.MaxOutstandingInvites
+		}
+
+		if config.Instance.Multiplayer.InviteWindow > 0 {
+			window = config.Instance.Multiplayer.InviteWindow
+		}
+	}
+
+	return maxInvites, time.Duration(window) * time.Second
 }
 
 // Sends a packet to all players in the game.
@@ -1192,8 +2173,8 @@ func (game *Game) sendPacketToPlayers(packet interface{}) {
 	}
 
 	for _, id := range game.spectators {
-		// Referee will have already gotten the packet above.
-		if id == game.Data.RefereeId && utils.Includes(game.Data.PlayerIds, id) {
+		// Referees in the game will have already gotten the packet above.
+		if game.isReferee(id) && utils.Includes(game.Data.PlayerIds, id) {
 			continue
 		}
 
@@ -1212,6 +2193,13 @@ func (game *Game) sendBotMessage(message string) {
 	chat.SendMessage(chat.Bot, game.chatChannel.Name, message)
 }
 
+// BroadcastChat Sends a message from a user to the game chat. It goes through the same checks as any other chat
+// message, so muted users and spammers are dropped, blocks are respected, and spectators are read-only unless
+// spectator chat is enabled. This must not be called while the game is locked, as the message may be a command.
+func (game *Game) BroadcastChat(sender *sessions.User, message string) {
+	chat.SendMessage(sender, game.chatChannel.Name, message)
+}
+
 // validateAndCacheSettings Checks the multiplayer settings to see if they are in an acceptable range
 func (game *Game) validateAndCacheSettings() {
 	data := game.Data
@@ -1222,10 +2210,15 @@ func (game *Game) validateAndCacheSettings() {
 		data.Name = censored
 	}
 
-	data.HasPassword = game.Password != ""
+	data.HasPassword = game.hasPassword()
 	data.MaxPlayers = utils.Clamp(data.MaxPlayers, 2, 16)
-	data.Ruleset = objects.MultiplayerGameRulesetFreeForAll
+	data.Ruleset = utils.Clamp(data.Ruleset, objects.MultiplayerGameRulesetFreeForAll, objects.MultiplayerGameRulesetBattleRoyale)
+	data.HealthType = utils.Clamp(data.HealthType, objects.MultiplayerGameHealthRegeneration, objects.MultiplayerGameHealthLives)
+	data.LifeCount = utils.Clamp(data.LifeCount, 1, maxLifeCount)
 	data.FreeModType = utils.Clamp(data.FreeModType, objects.MultiplayerGameFreeModNone, objects.MultiplayerGameFreeModRegular|objects.MultiplayerGameFreeModRate)
+	data.SpectatorAccess = utils.Clamp(data.SpectatorAccess, objects.MultiplayerGameSpectatorAccessPassword, objects.MultiplayerGameSpectatorAccessOpen)
+	data.BestOf = utils.Clamp(data.BestOf, 0, 99)
+	data.MinimumFps = utils.Clamp(data.MinimumFps, 0, 1000)
 
 	data.MapMD5 = utils.TruncateString(data.MapMD5, 64)
 	data.MapMD5Alternative = utils.TruncateString(data.MapMD5Alternative, 64)
@@ -1248,6 +2241,17 @@ func (game *Game) validateAndCacheSettings() {
 		data.NeedsDifficultyRatings = false
 	}
 
+	allowedModes := []common.Mode{}
+
+	for _, mode := range data.FilterAllowedGameModes {
+		if mode >= common.ModeKeys4 && mode < common.ModeEnumMaxValue && !utils.Includes(allowedModes, mode) {
+			allowedModes = append(allowedModes, mode)
+		}
+	}
+
+	data.FilterAllowedGameModes = allowedModes
+
+	// No allowed modes means every mode is allowed
 	if len(data.FilterAllowedGameModes) == 0 {
 		data.FilterAllowedGameModes = []common.Mode{common.ModeKeys4, common.ModeKeys7}
 	}
@@ -1255,6 +2259,18 @@ func (game *Game) validateAndCacheSettings() {
 	game.cacheMatchSettings()
 }
 
+// Returns a readable name for a spectator access setting
+func getSpectatorAccessName(access objects.MultiplayerGameSpectatorAccess) string {
+	switch access {
+	case objects.MultiplayerGameSpectatorAccessInvite:
+		return "invite only"
+	case objects.MultiplayerGameSpectatorAccessOpen:
+		return "open"
+	default:
+		return "password or invite"
+	}
+}
+
 // Removes inactive players from the game
 func (game *Game) removeInactivePlayers() {
 	go func() {
@@ -0,0 +1,66 @@
+package multiplayer
+
+import "fmt"
+
+type GameState int
+
+const (
+	GameStateWaiting    GameState = iota // Players are in the lobby waiting for the match to start
+	GameStateCountdown                   // The countdown to start the match is live
+	GameStateInProgress                  // The match is being played
+	GameStateResults                     // The match has ended and its results are being processed
+)
+
+// The states each state is able to move to
+var gameStateTransitions = map[GameState][]GameState{
+	GameStateWaiting:    {GameStateCountdown, GameStateInProgress},
+	GameStateCountdown:  {GameStateWaiting, GameStateInProgress},
+	GameStateInProgress: {GameStateResults},
+	GameStateResults:    {GameStateWaiting},
+}
+
+// GetState Returns the current state of the game
+func (game *Game) GetState() GameState {
+	return game.state
+}
+
+// SetState Moves the game to a new state. Returns an error if the game can't move to it from its current state.
+func (game *Game) SetState(state GameState) error {
+	if !isValidGameStateTransition(game.state, state) {
+		return fmt.Errorf("the game cannot go from %v to %v", getGameStateName(game.state), getGameStateName(state))
+	}
+
+	game.state = state
+
+	// The match is still in progress while its results are being processed
+	game.Data.InProgress = state == GameStateInProgress || state == GameStateResults
+	game.cacheMatchSettings()
+	return nil
+}
+
+// Returns if a game is able to move from one state to another
+func isValidGameStateTransition(from GameState, to GameState) bool {
+	for _, state := range gameStateTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns a readable name for a game state
+func getGameStateName(state GameState) string {
+	switch state {
+	case GameStateWaiting:
+		return "waiting"
+	case GameStateCountdown:
+		return "countdown"
+	case GameStateInProgress:
+		return "in progress"
+	case GameStateResults:
+		return "results"
+	default:
+		return "unknown"
+	}
+}
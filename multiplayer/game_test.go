@@ -0,0 +1,773 @@
+package multiplayer
+
+import (
+	"bytes"
+	"context"
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/scoring"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetNextRotationHost(t *testing.T) {
+	players := []int{1, 2, 3, 4}
+
+	if next := getNextRotationHost(players, 1); next != 2 {
+		t.Fatalf("expected host 2 after host 1, got %v", next)
+	}
+
+	if next := getNextRotationHost(players, 4); next != 1 {
+		t.Fatalf("expected rotation to wrap around to host 1, got %v", next)
+	}
+
+	if next := getNextRotationHost(players, 10); next != 1 {
+		t.Fatalf("expected the first player when the host isn't in the game, got %v", next)
+	}
+
+	if next := getNextRotationHost([]int{}, 1); next != -1 {
+		t.Fatalf("expected no host for an empty game, got %v", next)
+	}
+}
+
+// Fails every redis command right away, so game methods that cache their state are able to run without redis.
+// Counts the round trips that would have been made to redis.
+type unavailableRedis struct {
+	roundTrips int32
+}
+
+func (h *unavailableRedis) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	atomic.AddInt32(&h.roundTrips, 1)
+	return ctx, db.ErrRedisUnavailable
+}
+
+func (h *unavailableRedis) AfterProcess(context.Context, redis.Cmder) error {
+	return nil
+}
+
+func (h *unavailableRedis) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	atomic.AddInt32(&h.roundTrips, 1)
+	return ctx, db.ErrRedisUnavailable
+}
+
+func (h *unavailableRedis) AfterProcessPipeline(context.Context, []redis.Cmder) error {
+	return nil
+}
+
+// Returns the round trips counted since the last call
+func (h *unavailableRedis) takeRoundTrips() int32 {
+	return atomic.SwapInt32(&h.roundTrips, 0)
+}
+
+// Sets up redis and the lobby for tests that go through the full game methods
+func useTestEnvironment(t *testing.T) *unavailableRedis {
+	previousRedis, previousLobby := db.Redis, lobby
+	hook := &unavailableRedis{}
+
+	db.Redis = redis.NewClient(&redis.Options{})
+	db.Redis.AddHook(hook)
+
+	lobby = &multiplayerLobby{
+		users: map[int]*sessions.User{},
+		games: map[int]*Game{},
+		mutex: &sync.Mutex{},
+	}
+
+	t.Cleanup(func() {
+		db.Redis, lobby = previousRedis, previousLobby
+	})
+
+	return hook
+}
+
+var initializeChat sync.Once
+
+// Sets up the chat, so game methods are able to send bot messages. Messages to channels that aren't registered
+// are dropped. Must be called after config.Instance is set.
+func useTestChat() {
+	initializeChat.Do(func() {
+		chat.Initialize()
+
+		// The bot's session would otherwise be mistaken for the test user with the same id
+		_ = sessions.RemoveUser(chat.Bot)
+	})
+}
+
+func TestTransferHostThenRotate(t *testing.T) {
+	useTestEnvironment(t)
+
+	game := &Game{Data: &objects.MultiplayerGame{
+		HostId:            1,
+		PlayerIds:         []int{1, 2, 3, 4},
+		PlayersWithoutMap: []int{2},
+		IsHostRotation:    true,
+	}}
+
+	host := sessions.NewUser(nil, &db.User{Id: 1, Username: "host"})
+
+	if err := game.TransferHost(host, 2); err == nil || game.Data.HostId != 1 {
+		t.Fatalf("expected host not to be transferred to a player without the map, got %v", err)
+	}
+
+	if err := game.TransferHost(host, 3); err != nil || game.Data.HostId != 3 {
+		t.Fatalf("expected host to be transferred to 3, got host %v (%v)", game.Data.HostId, err)
+	}
+
+	// Rotation continues from the new host without skipping or repeating anyone
+	for _, want := range []int{4, 1, 2, 3} {
+		game.rotateHost()
+
+		if game.Data.HostId != want {
+			t.Fatalf("expected host %v, got %v", want, game.Data.HostId)
+		}
+	}
+}
+
+func TestCachedPlayerRoundTrips(t *testing.T) {
+	redisHook := useTestEnvironment(t)
+	game := &Game{Data: &objects.MultiplayerGame{Id: 1, GameId: "round-trips"}}
+
+	// Only online players are cached. Adding the sessions also tries to update redis, which is expected to fail here.
+	for id := 1; id <= 16; id++ {
+		user := sessions.NewUser(nil, &db.User{Id: id, Username: fmt.Sprintf("Player #%v", id)})
+		_ = sessions.AddUser(user)
+		t.Cleanup(func() { _ = sessions.RemoveUser(user) })
+
+		game.Data.PlayerIds = append(game.Data.PlayerIds, id)
+	}
+
+	redisHook.takeRoundTrips()
+
+	for _, id := range game.Data.PlayerIds {
+		game.cachePlayer(id)
+	}
+
+	if roundTrips := redisHook.takeRoundTrips(); roundTrips != 16 {
+		t.Fatalf("expected caching players one by one to take 16 round trips, took %v", roundTrips)
+	}
+
+	game.cacheAllPlayers()
+
+	if roundTrips := redisHook.takeRoundTrips(); roundTrips != 1 {
+		t.Fatalf("expected caching every player to take 1 round trip, took %v", roundTrips)
+	}
+
+	for _, id := range game.Data.PlayerIds {
+		game.deleteCachedPlayer(id)
+	}
+
+	if roundTrips := redisHook.takeRoundTrips(); roundTrips != 16 {
+		t.Fatalf("expected deleting players one by one to take 16 round trips, took %v", roundTrips)
+	}
+
+	game.deleteCachedGame(game.Data.PlayerIds)
+
+	if roundTrips := redisHook.takeRoundTrips(); roundTrips != 1 {
+		t.Fatalf("expected deleting the game to take 1 round trip, took %v", roundTrips)
+	}
+}
+
+func TestGameRedisKeysUsePrefix(t *testing.T) {
+	defer func(prefix string) { db.KeyPrefix = prefix }(db.KeyPrefix)
+	db.KeyPrefix = "staging:"
+
+	game := &Game{Data: &objects.MultiplayerGame{Id: 5, GameId: "abc"}}
+
+	keys := map[string]string{
+		game.getMatchSettingsRedisKey(): "staging:multiplayer:5",
+		game.getPlayerRedisKey(2):       "staging:multiplayer:5:player:2",
+		game.getPlayerScoreRedisKey(2):  "staging:multiplayer:abc:2",
+	}
+
+	for key, expected := range keys {
+		if key != expected {
+			t.Fatalf("expected %v, got %v", expected, key)
+		}
+	}
+}
+
+func TestIsTeamChangeBalanced(t *testing.T) {
+	if !isTeamChangeBalanced(1, 1, objects.MultiplayerTeamRed) {
+		t.Fatal("expected joining an even team to be allowed")
+	}
+
+	if isTeamChangeBalanced(2, 1, objects.MultiplayerTeamRed) {
+		t.Fatal("expected joining the larger team to be refused")
+	}
+
+	if !isTeamChangeBalanced(2, 1, objects.MultiplayerTeamBlue) {
+		t.Fatal("expected joining the smaller team to be allowed")
+	}
+}
+
+func TestTeamWinsAcrossRounds(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{}}
+
+	rounds := []TeamScores{
+		{Red: 10, Blue: 5},
+		{Red: 3, Blue: 8},
+		{Red: 7, Blue: 7},
+		{Red: 12, Blue: 4},
+	}
+
+	for _, scores := range rounds {
+		if team, ok := getTeamMatchWinner(scores); ok {
+			game.addTeamWin(team)
+		}
+	}
+
+	if game.Data.TeamRedWins != 2 || game.Data.TeamBlueWins != 1 {
+		t.Fatalf("expected 2 - 1 after four rounds with a draw, got %v - %v", game.Data.TeamRedWins, game.Data.TeamBlueWins)
+	}
+}
+
+func TestPlayerHealthLosesLives(t *testing.T) {
+	health := &playerHealth{Health: maxPlayerHealth, Lives: 2}
+
+	misses := make([]common.Judgements, 17)
+
+	for i := range misses {
+		misses[i] = common.JudgementMiss
+	}
+
+	if health.applyJudgements(misses, objects.MultiplayerGameHealthLives) {
+		t.Fatal("expected the player to survive their first life running out")
+	}
+
+	if health.Lives != 1 {
+		t.Fatalf("expected the player to have 1 life left, got %v", health.Lives)
+	}
+
+	if health.applyJudgements([]common.Judgements{common.JudgementMiss, common.JudgementMarv}, objects.MultiplayerGameHealthLives); health.Health != maxPlayerHealth-6 {
+		t.Fatalf("expected health not to regenerate with the lives health type, got %v", health.Health)
+	}
+
+	if !health.applyJudgements(misses, objects.MultiplayerGameHealthLives) {
+		t.Fatal("expected the player to be eliminated once they are out of lives")
+	}
+}
+
+func TestReadyCheckFailures(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{
+		PlayerIds:         []int{1, 2, 3, 4},
+		PlayersReady:      []int{1},
+		PlayersWithoutMap: []int{3},
+		RefereeIds:        []int{4},
+	}}
+
+	// The referee and players without the map aren't expected to ready up
+	failed := game.getReadyCheckFailures()
+
+	if len(failed) != 1 || failed[0] != 2 {
+		t.Fatalf("expected only player 2 to fail the ready check, got %v", failed)
+	}
+
+	game.Data.PlayersReady = append(game.Data.PlayersReady, 2)
+
+	if failed = game.getReadyCheckFailures(); len(failed) != 0 {
+		t.Fatalf("expected no failures once everyone is ready, got %v", failed)
+	}
+}
+
+func TestRefereePermissions(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{
+		HostId:      1,
+		HasPassword: true,
+		PlayerIds:   []int{1, 2, 3},
+		RefereeIds:  []int{3, 4},
+	}}
+
+	game.storePassword("secret")
+
+	player := sessions.NewUser(nil, &db.User{Id: 2, Username: "player"})
+	referee := sessions.NewUser(nil, &db.User{Id: 3, Username: "referee"})
+	spectatingReferee := sessions.NewUser(nil, &db.User{Id: 4, Username: "spectating referee"})
+
+	if game.isUserHost(player) {
+		t.Fatal("expected a player not to have host permissions")
+	}
+
+	if !game.isUserHost(referee) || !game.isUserHost(spectatingReferee) {
+		t.Fatal("expected every referee to have host permissions")
+	}
+
+	if game.canSpectate(player, "wrong") {
+		t.Fatal("expected a player to need the password to spectate")
+	}
+
+	if !game.canSpectate(spectatingReferee, "wrong") {
+		t.Fatal("expected a referee to spectate without the password")
+	}
+
+	if err := game.SetPlayerScore(player, 1, ScoreOverride{}); err == nil || err.Error() != "only referees are able to override scores" {
+		t.Fatalf("expected a player not to be able to override scores, got %v", err)
+	}
+
+	// Referees get past the permission check, and are only stopped because there's no match in progress
+	if err := game.SetPlayerScore(spectatingReferee, 1, ScoreOverride{}); err == nil || err.Error() != "scores can only be overridden while the match is in progress" {
+		t.Fatalf("expected a referee to pass the permission check, got %v", err)
+	}
+
+	if err := game.AddReferee(player, 2); err == nil || game.isReferee(2) {
+		t.Fatalf("expected a player not to be able to add referees, got %v", err)
+	}
+
+	if err := game.RemoveReferee(nil, 1); err == nil {
+		t.Fatal("expected removing a user who isn't a referee to fail")
+	}
+}
+
+func TestRefereePauseWaitsForEveryReferee(t *testing.T) {
+	game := &Game{
+		Data:             &objects.MultiplayerGame{RefereeIds: []int{3, 4}},
+		state:            GameStateInProgress,
+		pausedAt:         1,
+		pausedForReferee: true,
+		refereeTimers:    map[int]*time.Timer{4: time.NewTimer(time.Hour)},
+	}
+
+	defer game.refereeTimers[4].Stop()
+
+	// Referee 4's role is still being held, so the match stays paused when referee 3 comes back
+	game.resumeAfterRefereeReturns()
+
+	if !game.IsPaused() || !game.pausedForReferee {
+		t.Fatal("expected the match to stay paused while another referee's role is held")
+	}
+}
+
+func TestOccupiedPlayerCount(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{
+		PlayerIds:  []int{1, 2, 3, 4},
+		RefereeIds: []int{3, 5},
+	}}
+
+	// Referee 5 is only spectating, so only referee 3 is in the player list without taking up a slot
+	if count := game.getOccupiedPlayerCount(); count != 3 {
+		t.Fatalf("expected 3 occupied slots, got %v", count)
+	}
+
+	game.Data.RefereeIds = append(game.Data.RefereeIds, 4)
+
+	if count := game.getOccupiedPlayerCount(); count != 2 {
+		t.Fatalf("expected 2 occupied slots once another player is a referee, got %v", count)
+	}
+
+	game.Data.RefereeIds = []int{}
+
+	if count := game.getOccupiedPlayerCount(); count != 4 {
+		t.Fatalf("expected every player to take up a slot without referees, got %v", count)
+	}
+}
+
+func TestReservedSlotSurvivesWrongPassword(t *testing.T) {
+	useTestEnvironment(t)
+
+	game := &Game{Data: &objects.MultiplayerGame{
+		HostId:      1,
+		HasPassword: true,
+		MaxPlayers:  2,
+		PlayerIds:   []int{1},
+	}}
+
+	game.storePassword("secret")
+
+	host := sessions.NewUser(nil, &db.User{Id: 1, Username: "host"})
+	reserved := sessions.NewUser(nil, &db.User{Id: 2, Username: "reserved"})
+	other := sessions.NewUser(nil, &db.User{Id: 3, Username: "other"})
+
+	for _, user := range []*sessions.User{host, reserved, other} {
+		user := user
+		_ = sessions.AddUser(user)
+		t.Cleanup(func() { _ = sessions.RemoveUser(user) })
+	}
+
+	if err := game.ReserveSlot(host, reserved.Info.Id); err != nil {
+		t.Fatalf("expected the slot to be reserved, got %v", err)
+	}
+
+	game.AddPlayer(reserved.Info.Id, "wrong")
+
+	if utils.Includes(game.Data.PlayerIds, reserved.Info.Id) || !game.IsSlotReserved(reserved.Info.Id) {
+		t.Fatal("expected a failed password attempt to keep the reservation")
+	}
+
+	// The lobby is still full, since the only free slot is held for the reserved user
+	game.AddPlayer(other.Info.Id, "secret")
+
+	if utils.Includes(game.Data.PlayerIds, other.Info.Id) {
+		t.Fatal("expected a full lobby to reject users without a reservation")
+	}
+}
+
+func TestSpectatorChatPermissions(t *testing.T) {
+	useTestEnvironment(t)
+
+	game := &Game{Data: &objects.MultiplayerGame{
+		HostId:     1,
+		PlayerIds:  []int{1},
+		RefereeIds: []int{3},
+	}}
+
+	host := sessions.NewUser(nil, &db.User{Id: 1, Username: "host"})
+	spectator := sessions.NewUser(nil, &db.User{Id: 2, Username: "spectator"})
+	referee := sessions.NewUser(nil, &db.User{Id: 3, Username: "referee"})
+
+	game.setSpectators([]int{2, 3})
+
+	if !game.canSendChatMessage(host) || !game.canSendChatMessage(referee) {
+		t.Fatal("expected players and referees to always be able to chat")
+	}
+
+	if game.canSendChatMessage(spectator) {
+		t.Fatal("expected spectators to be read-only while spectator chat is disabled")
+	}
+
+	game.Data.SpectatorsCanChat = true
+	game.updateChatPermissions()
+
+	if !game.canSendChatMessage(spectator) {
+		t.Fatal("expected spectators to be able to chat once spectator chat is enabled")
+	}
+
+	// The chat checks permissions without locking the game, while the game keeps changing under its own lock
+	game.mutex = utils.NewMutex()
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			game.RunLocked(func() {
+				game.Data.SpectatorsCanChat = i%2 == 0
+				game.updateChatPermissions()
+				game.setSpectators(append(game.spectators, 100+i))
+			})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			game.canSendChatMessage(spectator)
+		}
+	}()
+
+	wg.Wait()
+}
+
+func TestBlockedInviteDoesNotUseInviteLimit(t *testing.T) {
+	useTestEnvironment(t)
+
+	previousConfig := config.Instance
+	t.Cleanup(func() { config.Instance = previousConfig })
+
+	config.Instance = &config.Configuration{}
+	config.Instance.Multiplayer.MaxOutstandingInvites = 1
+	useTestChat()
+
+	game := &Game{
+		Data:          &objects.MultiplayerGame{Id: 1, PlayerIds: []int{1}},
+		chatChannel:   &chat.Channel{Name: "#multiplayer_invite_test"},
+		inviteSenders: map[int]int{},
+	}
+
+	sender := sessions.NewUser(nil, &db.User{Id: 1, Username: "sender"})
+	blocking := sessions.NewUser(nil, &db.User{Id: 2, Username: "blocking"})
+	other := sessions.NewUser(nil, &db.User{Id: 3, Username: "other"})
+
+	blocking.SetBlockedIds([]int{sender.Info.Id})
+
+	if err := game.SendInvite(sender, blocking); err != nil || utils.Includes(game.playersInvited, blocking.Info.Id) {
+		t.Fatalf("expected the invite to look sent without inviting the user who blocked the sender, got %v", err)
+	}
+
+	if err := game.SendInvite(sender, other); err != nil || !utils.Includes(game.playersInvited, other.Info.Id) {
+		t.Fatalf("expected the blocked invite not to use up the sender's only invite, got %v", err)
+	}
+}
+
+func TestValidatePlayerModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		freeMod   objects.MultiplayerGameFreeMod
+		global    common.Mods
+		mods      common.Mods
+		wantError bool
+	}{
+		{"no free mod, no mods", objects.MultiplayerGameFreeModNone, 0, 0, false},
+		{"no free mod, matching global", objects.MultiplayerGameFreeModNone, common.ModNoFail, common.ModNoFail, false},
+		{"no free mod, extra mod", objects.MultiplayerGameFreeModNone, 0, common.ModMirror, true},
+		{"no free mod, extra rate", objects.MultiplayerGameFreeModNone, 0, common.ModSpeed12X, true},
+		{"free mod, extra mod", objects.MultiplayerGameFreeModRegular, 0, common.ModMirror, false},
+		{"free mod, extra rate", objects.MultiplayerGameFreeModRegular, 0, common.ModSpeed12X, true},
+		{"free rate, extra rate", objects.MultiplayerGameFreeModRate, common.ModSpeed11X, common.ModSpeed12X, false},
+		{"free rate, extra mod", objects.MultiplayerGameFreeModRate, 0, common.ModMirror, true},
+		{"both, extra mod and rate", objects.MultiplayerGameFreeModRegular | objects.MultiplayerGameFreeModRate, 0, common.ModMirror | common.ModSpeed12X, false},
+		{"both, illegal combination", objects.MultiplayerGameFreeModRegular | objects.MultiplayerGameFreeModRate, 0, common.ModAutoplay, true},
+	}
+
+	for _, test := range tests {
+		err := validatePlayerModifiers(test.freeMod, test.global, test.mods)
+
+		if (err != nil) != test.wantError {
+			t.Errorf("%v: expected error %v, got %v", test.name, test.wantError, err)
+		}
+	}
+}
+
+func TestMapVoteWinner(t *testing.T) {
+	proposals := []*packets.ClientChangeGameMap{{MD5: "a"}, {MD5: "b"}, {MD5: "c"}}
+
+	if winner := getMapVoteWinner(proposals, map[int]string{1: "b", 2: "c", 3: "b"}); winner.MD5 != "b" {
+		t.Fatalf("expected the map with the most votes to win, got %v", winner.MD5)
+	}
+
+	// Ties go to the map that was proposed first
+	if winner := getMapVoteWinner(proposals, map[int]string{1: "c", 2: "b"}); winner.MD5 != "b" {
+		t.Fatalf("expected the earliest proposed map to win a tie, got %v", winner.MD5)
+	}
+
+	if winner := getMapVoteWinner([]*packets.ClientChangeGameMap{}, map[int]string{}); winner != nil {
+		t.Fatalf("expected no winner without proposals, got %v", winner.MD5)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{}}
+
+	if !game.CheckPassword("anything") {
+		t.Fatalf("expected a game without a password to accept any attempt")
+	}
+
+	game.storePassword("secret")
+
+	if bytes.Contains(game.passwordHash, []byte("secret")) {
+		t.Fatalf("expected the password to only be stored as a hash")
+	}
+
+	if !game.CheckPassword("secret") || game.CheckPassword("Secret") || game.CheckPassword("") {
+		t.Fatalf("expected only the correct password to be accepted")
+	}
+
+	game.storePassword("")
+
+	if game.hasPassword() {
+		t.Fatalf("expected an empty password to remove it")
+	}
+}
+
+func TestValidateMapDifficulty(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{FilterMinDifficultyRating: 3, FilterMaxDifficultyRating: 5}}
+
+	if err := game.validateMapDifficulty(4); err != nil {
+		t.Fatalf("expected a map inside the range to be allowed, got %v", err)
+	}
+
+	if game.validateMapDifficulty(2.9) == nil || game.validateMapDifficulty(5.1) == nil {
+		t.Fatalf("expected maps outside the range to be rejected")
+	}
+
+	// A maximum of zero leaves the range unbounded
+	game.Data.FilterMaxDifficultyRating = 0
+
+	if err := game.validateMapDifficulty(50); err != nil {
+		t.Fatalf("expected no maximum when it is zero, got %v", err)
+	}
+
+	if err := game.validateMapDifficulty(2); err == nil || err.Error() != "the map's difficulty must be at least 3" {
+		t.Fatalf("expected the error to only mention the minimum when there is no maximum, got %v", err)
+	}
+}
+
+func TestValidateMapGameMode(t *testing.T) {
+	game := &Game{Data: &objects.MultiplayerGame{FilterAllowedGameModes: []common.Mode{common.ModeKeys4}}}
+
+	if err := game.validateMapGameMode(common.ModeKeys4); err != nil {
+		t.Fatalf("expected an allowed mode to be accepted, got %v", err)
+	}
+
+	if game.validateMapGameMode(common.ModeKeys7) == nil {
+		t.Fatalf("expected a mode that isn't allowed to be rejected")
+	}
+
+	game.Data.FilterAllowedGameModes = []common.Mode{}
+
+	if err := game.validateMapGameMode(common.ModeKeys7); err != nil {
+		t.Fatalf("expected every mode to be allowed when none are set, got %v", err)
+	}
+}
+
+func TestGameStateTransitions(t *testing.T) {
+	states := []GameState{GameStateWaiting, GameStateCountdown, GameStateInProgress, GameStateResults}
+
+	valid := map[GameState][]GameState{
+		GameStateWaiting:    {GameStateCountdown, GameStateInProgress},
+		GameStateCountdown:  {GameStateWaiting, GameStateInProgress},
+		GameStateInProgress: {GameStateResults},
+		GameStateResults:    {GameStateWaiting},
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			want := false
+
+			for _, state := range valid[from] {
+				if state == to {
+					want = true
+				}
+			}
+
+			if got := isValidGameStateTransition(from, to); got != want {
+				t.Errorf("expected %v -> %v to be valid: %v, got %v", getGameStateName(from), getGameStateName(to), want, got)
+			}
+		}
+	}
+}
+
+func TestPlayerDownloadProgress(t *testing.T) {
+	useTestEnvironment(t)
+
+	game := &Game{
+		Data:                   &objects.MultiplayerGame{PlayerIds: []int{1, 2}, PlayersWithoutMap: []int{2}},
+		playerDownloadProgress: map[int]int{},
+	}
+
+	if progress := game.GetPlayerDownloadProgress(1); progress != 100 {
+		t.Fatalf("expected a player with the map to be fully downloaded, got %v", progress)
+	}
+
+	game.SetPlayerDownloadProgress(2, 40)
+
+	if progress := game.GetPlayerDownloadProgress(2); progress != 40 {
+		t.Fatalf("expected download progress of 40, got %v", progress)
+	}
+
+	// Progress is only tracked for players who don't have the map
+	game.SetPlayerDownloadProgress(1, 10)
+
+	if _, ok := game.playerDownloadProgress[1]; ok {
+		t.Fatalf("expected no download progress for a player who has the map")
+	}
+}
+
+func TestSpectatorCapUnderConcurrentJoins(t *testing.T) {
+	previousConfig := config.Instance
+	t.Cleanup(func() { config.Instance = previousConfig })
+
+	startingTotal := GetTotalSpectatorCount()
+
+	config.Instance = &config.Configuration{}
+	config.Instance.Multiplayer.MaxTotalSpectators = startingTotal + 5
+
+	games := make([]*Game, 4)
+
+	for i := range games {
+		games[i] = &Game{mutex: utils.NewMutex(), Data: &objects.MultiplayerGame{}}
+	}
+
+	var added int32
+	wg := sync.WaitGroup{}
+
+	// Every game is locked on its own, so only the server-wide count keeps them from going over the cap together
+	for id := 1; id <= 40; id++ {
+		wg.Add(1)
+
+		go func(id int) {
+			defer wg.Done()
+
+			game := games[id%len(games)]
+
+			game.RunLocked(func() {
+				if game.tryAddSpectator(id) {
+					atomic.AddInt32(&added, 1)
+				}
+			})
+		}(id)
+	}
+
+	wg.Wait()
+
+	t.Cleanup(func() {
+		for _, game := range games {
+			game.setSpectators([]int{})
+		}
+	})
+
+	if added != 5 || GetTotalSpectatorCount() != startingTotal+5 {
+		t.Fatalf("expected 5 spectators to be let in, got %v (total %v)", added, GetTotalSpectatorCount()-startingTotal)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	useTestEnvironment(t)
+
+	previousConfig := config.Instance
+	t.Cleanup(func() { config.Instance = previousConfig })
+
+	config.Instance = &config.Configuration{}
+	useTestChat()
+
+	game := &Game{
+		Data:         &objects.MultiplayerGame{Id: 1, HostId: 1, PlayerIds: []int{1, 2}},
+		chatChannel:  &chat.Channel{Name: "#multiplayer_pause_test"},
+		playerScores: map[int]*scoring.ScoreProcessor{},
+	}
+
+	host := sessions.NewUser(nil, &db.User{Id: 1, Username: "host"})
+	player := sessions.NewUser(nil, &db.User{Id: 2, Username: "player"})
+
+	if err := game.Pause(host); err == nil || game.IsPaused() {
+		t.Fatal("expected the match not to be paused before it's in progress")
+	}
+
+	game.state = GameStateInProgress
+	game.Data.InProgress = true
+
+	if err := game.Pause(player); err == nil || game.IsPaused() {
+		t.Fatal("expected a player who isn't the host or a referee not to be able to pause")
+	}
+
+	if err := game.Pause(host); err != nil || !game.IsPaused() || game.pausedById != host.Info.Id {
+		t.Fatalf("expected the host to pause the match, got %v", err)
+	}
+
+	if err := game.Pause(host); err == nil {
+		t.Fatal("expected pausing an already paused match to fail")
+	}
+
+	// Pretend the match started 10 seconds ago and has been paused for the last 5
+	now := time.Now().UnixMilli()
+	game.matchStartTime = now - 10000
+	game.pausedAt = now - 5000
+
+	if err := game.Resume(host); err != nil || game.IsPaused() {
+		t.Fatalf("expected the host to resume the match, got %v", err)
+	}
+
+	if elapsed := time.Now().UnixMilli() - game.matchStartTime; elapsed < 5000 || elapsed > 6000 {
+		t.Fatalf("expected the pause not to count towards the match's progress, got %vms elapsed", elapsed)
+	}
+
+	if err := game.Pause(nil); err != nil || !game.IsPaused() {
+		t.Fatalf("expected the server to pause the match, got %v", err)
+	}
+
+	game.EndGame(false)
+
+	if game.IsPaused() || game.pausedById != 0 {
+		t.Fatal("expected ending the match to clear the pause")
+	}
+}
@@ -1,8 +1,11 @@
 package multiplayer
 
 import (
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
+	"fmt"
 	"log"
 	"sync"
 )
@@ -26,6 +29,13 @@ func InitializeLobby() {
 		games: map[int]*Game{},
 		mutex: &sync.Mutex{},
 	}
+	sessions.AddDisconnectHandler(func(user *sessions.User) {
+		if game := GetGameById(user.GetMultiplayerGameId()); game != nil {
+			game.RemovePlayer(user.Info.Id)
+		}
+
+		RemoveUserFromLobby(user)
+	})
 }
 
 // AddUserToLobby Adds a user to the multiplayer lobby
@@ -45,7 +55,10 @@ func RemoveUserFromLobby(user *sessions.User) {
 	lobby.mutex.Lock()
 	defer lobby.mutex.Unlock()
 
-	delete(lobby.users, user.Info.Id)
+	// The user may still be in the lobby on another device
+	if lobby.users[user.Info.Id] == user {
+		delete(lobby.users, user.Info.Id)
+	}
 }
 
 // AddGameToLobby Adds a game to the multiplayer lobby list
@@ -59,6 +72,26 @@ func AddGameToLobby(game *Game) {
 	log.Printf("Multiplayer Game `%v (#%v)` was created.\n", game.Data.Name, game.Data.Id)
 }
 
+// AnnounceGameCreation Announces a newly created game in the configured announcement channel.
+// Private and tournament games are never announced.
+func AnnounceGameCreation(game *Game) {
+	if config.Instance == nil || config.Instance.Multiplayer.AnnounceChannel == "" {
+		return
+	}
+
+	if game.Data.HasPassword || game.Data.IsTournamentMode {
+		return
+	}
+
+	message := fmt.Sprintf("A new multiplayer game has been created: %v", game.Data.Name)
+
+	if game.Data.MapName != "" {
+		message += fmt.Sprintf(" (%v)", game.Data.MapName)
+	}
+
+	chat.SendMessage(chat.Bot, config.Instance.Multiplayer.AnnounceChannel, message)
+}
+
 // RemoveGameFromLobby Removes a game from the multiplayer lobby list
 func RemoveGameFromLobby(game *Game) {
 	lobby.mutex.Lock()
@@ -80,6 +113,49 @@ func GetGameById(id int) *Game {
 	return lobby.games[id]
 }
 
+// GetUserGame Returns the game a user is in. If the game they were in no longer exists, it is cleared from
+// their session and they're told about it, so they don't get stuck referencing a dead game.
+func GetUserGame(user *sessions.User) *Game {
+	id := user.GetMultiplayerGameId()
+
+	if id == 0 {
+		return nil
+	}
+
+	game := GetGameById(id)
+
+	if game != nil {
+		return game
+	}
+
+	user.SetMultiplayerGameId(0)
+	sessions.SendPacketToUser(packets.NewServerJoinGameFailed(packets.JoinGameErrorMatchNoExists), user)
+	sessions.SendPacketToUser(packets.NewServerNotificationError("The multiplayer game you were in no longer exists."), user)
+	return nil
+}
+
+// GetLobbyUserCount Returns the amount of users currently in the multiplayer lobby
+func GetLobbyUserCount() int {
+	lobby.mutex.Lock()
+	defer lobby.mutex.Unlock()
+
+	return len(lobby.users)
+}
+
+// GetGames Returns all the games that are currently in the lobby
+func GetGames() []*Game {
+	lobby.mutex.Lock()
+	defer lobby.mutex.Unlock()
+
+	games := make([]*Game, 0, len(lobby.games))
+
+	for _, game := range lobby.games {
+		games = append(games, game)
+	}
+
+	return games
+}
+
 // GetGameByIdString Retrieves a game by its stringified id
 func GetGameByIdString(id string) *Game {
 	lobby.mutex.Lock()
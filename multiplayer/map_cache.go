@@ -0,0 +1,81 @@
+package multiplayer
+
+import (
+	"database/sql"
+	"errors"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"strings"
+	"sync"
+	"time"
+)
+
+type cachedSongMap struct {
+	song      *db.SongMap
+	expiresAt int64
+}
+
+const songMapCacheDuration = 30 * time.Second
+
+var (
+	songMapCache      = map[int]*cachedSongMap{}
+	songMapCacheMutex = &sync.Mutex{}
+
+	errMapNotFound    = errors.New("the selected map does not exist")
+	errMapMd5Mismatch = errors.New("the selected map does not match the version on the server")
+)
+
+// Retrieves a map from the database, caching the result briefly so rapid map changes don't repeatedly hit the db.
+func getCachedSongMap(id int) (*db.SongMap, error) {
+	songMapCacheMutex.Lock()
+	defer songMapCacheMutex.Unlock()
+
+	now := time.Now().UnixMilli()
+
+	if cached, ok := songMapCache[id]; ok && cached.expiresAt > now {
+		return cached.song, nil
+	}
+
+	song, err := db.GetSongMapById(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Clear out stale entries, so the cache doesn't grow forever.
+	for key, cached := range songMapCache {
+		if cached.expiresAt <= now {
+			delete(songMapCache, key)
+		}
+	}
+
+	songMapCache[id] = &cachedSongMap{song: song, expiresAt: now + songMapCacheDuration.Milliseconds()}
+	return song, nil
+}
+
+// Validates a client-provided map selection against the database and overwrites the
+// client-provided values with the authoritative ones. Unsubmitted maps (id <= 0) are not validated.
+func validateMapSelection(packet *packets.ClientChangeGameMap) error {
+	if packet.MapId <= 0 {
+		return nil
+	}
+
+	song, err := getCachedSongMap(packet.MapId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errMapNotFound
+		}
+
+		return err
+	}
+
+	if !strings.EqualFold(song.Md5.String, packet.MD5) && !strings.EqualFold(song.AlternativeMd5.String, packet.MD5) {
+		return errMapMd5Mismatch
+	}
+
+	packet.MapsetId = song.MapsetId
+	packet.Mode = song.GameMode
+	packet.DifficultyRating = song.DifficultyRating
+	return nil
+}
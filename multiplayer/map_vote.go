@@ -0,0 +1,195 @@
+package multiplayer
+
+import (
+	"errors"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// StartMapVote Lets the players propose and vote on the next map. Once the vote ends, the map with the most votes
+// is selected. Ties go to the map that was proposed first.
+func (game *Game) StartMapVote(requester *sessions.User, duration time.Duration) error {
+	if game.Data.InProgress {
+		return errors.New("the match is in progress")
+	}
+
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to start a map vote")
+	}
+
+	if game.mapVoteTimer != nil {
+		return errors.New("a map vote is already in progress")
+	}
+
+	if duration <= 0 {
+		return errors.New("the map vote must last longer than zero seconds")
+	}
+
+	game.mapVoteProposals = []*packets.ClientChangeGameMap{}
+	game.mapVotes = map[int]string{}
+	game.mapVoteEndTime = time.Now().Add(duration).UnixMilli()
+	game.mapVoteTimer = time.AfterFunc(duration, func() {
+		game.RunLocked(func() {
+			game.finishMapVote()
+		})
+	})
+
+	game.sendBotMessage(fmt.Sprintf("A map vote has started and will end in %v seconds. "+
+		"Select a map or use !mp vote <map id> to propose and vote for one.", int(duration.Seconds())))
+	game.broadcastMapVote()
+	return nil
+}
+
+// ProposeMap Adds a map to the live map vote and votes for it on behalf of the player who proposed it
+func (game *Game) ProposeMap(user *sessions.User, proposal *packets.ClientChangeGameMap) error {
+	if game.mapVoteTimer == nil {
+		return errors.New("there is no map vote in progress")
+	}
+
+	if !utils.Includes(game.Data.PlayerIds, user.Info.Id) || game.isReferee(user.Info.Id) {
+		return errors.New("only players are able to propose maps")
+	}
+
+	if getMapVoteProposal(game.mapVoteProposals, proposal.MD5) == nil {
+		if err := validateMapSelection(proposal); err != nil {
+			if err != errMapNotFound && err != errMapMd5Mismatch {
+				log.Printf("Error validating proposed multiplayer map %v - %v\n", proposal.MapId, err)
+				err = errors.New("there was an error while validating the selected map")
+			}
+
+			return err
+		}
+
+		if err := game.validateMapDifficulty(proposal.DifficultyRating); err != nil {
+			return err
+		}
+
+		if err := game.validateMapGameMode(proposal.Mode); err != nil {
+			return err
+		}
+
+		game.mapVoteProposals = append(game.mapVoteProposals, proposal)
+		game.sendBotMessage(fmt.Sprintf("%v has proposed: %v.", user.Info.Username, proposal.Name))
+	}
+
+	return game.CastVote(user.Info.Id, proposal.MD5)
+}
+
+// CastVote Votes for a map that has been proposed in the live map vote. Players can change their vote until it ends.
+func (game *Game) CastVote(userId int, mapMd5 string) error {
+	if game.mapVoteTimer == nil {
+		return errors.New("there is no map vote in progress")
+	}
+
+	if !utils.Includes(game.Data.PlayerIds, userId) || game.isReferee(userId) {
+		return errors.New("only players are able to vote")
+	}
+
+	proposal := getMapVoteProposal(game.mapVoteProposals, mapMd5)
+
+	if proposal == nil {
+		return errors.New("that map hasn't been proposed")
+	}
+
+	game.mapVotes[userId] = proposal.MD5
+	game.broadcastMapVote()
+	return nil
+}
+
+// Ends the live map vote and changes to the winning map
+func (game *Game) finishMapVote() {
+	// The vote may have been cleared while the timer was waiting on the lock
+	if game.mapVoteTimer == nil {
+		return
+	}
+
+	winner := getMapVoteWinner(game.mapVoteProposals, game.mapVotes)
+	game.broadcastMapVote()
+	game.clearMapVote()
+
+	if winner == nil {
+		game.sendBotMessage("The map vote has ended without any maps being proposed.")
+		return
+	}
+
+	game.sendBotMessage(fmt.Sprintf("The map vote has ended. The winning map is: %v.", winner.Name))
+
+	if game.Data.InProgress {
+		return
+	}
+
+	game.ChangeMap(nil, winner)
+}
+
+// Stops the map vote if one is live
+func (game *Game) clearMapVote() {
+	if game.mapVoteTimer != nil {
+		game.mapVoteTimer.Stop()
+		game.mapVoteTimer = nil
+	}
+
+	game.mapVoteEndTime = 0
+	game.mapVoteProposals = []*packets.ClientChangeGameMap{}
+	game.mapVotes = map[int]string{}
+}
+
+// Sends every player the current tally of the map vote
+func (game *Game) broadcastMapVote() {
+	game.sendPacketToPlayers(packets.NewServerGameMapVote(game.mapVoteEndTime, getMapVoteTally(game.mapVoteProposals, game.mapVotes)))
+}
+
+// IsMapVoteActive Returns if players are currently voting on the next map
+func (game *Game) IsMapVoteActive() bool {
+	return game.mapVoteTimer != nil
+}
+
+// Returns the amount of votes for each proposed map, in the order they were proposed
+func getMapVoteTally(proposals []*packets.ClientChangeGameMap, votes map[int]string) []*objects.MultiplayerGameMapVote {
+	tally := make([]*objects.MultiplayerGameMapVote, 0, len(proposals))
+
+	for _, proposal := range proposals {
+		mapVote := &objects.MultiplayerGameMapVote{MD5: proposal.MD5, MapId: proposal.MapId, Name: proposal.Name}
+
+		for _, md5 := range votes {
+			if md5 == proposal.MD5 {
+				mapVote.Votes++
+			}
+		}
+
+		tally = append(tally, mapVote)
+	}
+
+	return tally
+}
+
+// Returns the map with the most votes. Ties go to the map that was proposed first.
+func getMapVoteWinner(proposals []*packets.ClientChangeGameMap, votes map[int]string) *packets.ClientChangeGameMap {
+	var winner *packets.ClientChangeGameMap
+	mostVotes := -1
+
+	for i, mapVote := range getMapVoteTally(proposals, votes) {
+		if mapVote.Votes > mostVotes {
+			winner = proposals[i]
+			mostVotes = mapVote.Votes
+		}
+	}
+
+	return winner
+}
+
+// Returns the proposed map with the given md5, or nil if it hasn't been proposed
+func getMapVoteProposal(proposals []*packets.ClientChangeGameMap, md5 string) *packets.ClientChangeGameMap {
+	for _, proposal := range proposals {
+		if strings.EqualFold(proposal.MD5, md5) {
+			return proposal
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package multiplayer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+)
+
+const passwordSaltLength int = 16 // The amount of random bytes used to salt game passwords
+
+// CheckPassword Returns if the attempt matches the game's password. Games without a password accept any attempt.
+func (game *Game) CheckPassword(attempt string) bool {
+	if !game.hasPassword() {
+		return true
+	}
+
+	return subtle.ConstantTimeCompare(hashGamePassword(game.passwordSalt, attempt), game.passwordHash) == 1
+}
+
+// Replaces the game's password with a salted hash of the new one. An empty password removes it.
+func (game *Game) storePassword(password string) {
+	if password == "" {
+		game.passwordSalt = nil
+		game.passwordHash = nil
+		return
+	}
+
+	salt := make([]byte, passwordSaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		log.Printf("Failed to generate a salt for the password of game #%v - %v\n", game.Data.Id, err)
+	}
+
+	game.passwordSalt = salt
+	game.passwordHash = hashGamePassword(salt, password)
+}
+
+// Returns if the game has a password set
+func (game *Game) hasPassword() bool {
+	return len(game.passwordHash) != 0
+}
+
+// Hashes a password with the given salt
+func hashGamePassword(salt []byte, password string) []byte {
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write([]byte(password))
+	return hash.Sum(nil)
+}
@@ -0,0 +1,80 @@
+package multiplayer
+
+import (
+	"errors"
+	"example.com/Quaver/Z/chat"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Pause Pauses the match in progress, such as when a player disconnects during a tournament match.
+// Only the host or referee is able to do this.
+func (game *Game) Pause(requester *sessions.User) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host or referee is able to pause the match")
+	}
+
+	if game.state != GameStateInProgress {
+		return errors.New("the match is not in progress")
+	}
+
+	if game.IsPaused() {
+		return errors.New("the match is already paused")
+	}
+
+	pausedBy := getRequesterOrBot(requester)
+	game.pausedAt = time.Now().UnixMilli()
+	game.pausedById = pausedBy.Info.Id
+
+	log.Printf("[MP #%v] Match paused by #%v\n", game.Data.Id, game.pausedById)
+	game.sendBotMessage(fmt.Sprintf("The match has been paused by %v.", pausedBy.Info.Username))
+	game.sendPacketToPlayers(packets.NewServerGamePauseChanged(true, game.pausedById))
+	return nil
+}
+
+// Resume Resumes a paused match. The match's start time is moved forward by the time it spent paused,
+// so the pause doesn't count towards the match's progress.
+func (game *Game) Resume(requester *sessions.User) error {
+	if !game.isUserHost(requester) {
+		return errors.New("only the host or referee is able to resume the match")
+	}
+
+	if !game.IsPaused() {
+		return errors.New("the match is not paused")
+	}
+
+	resumedBy := getRequesterOrBot(requester)
+	pausedFor := time.Now().UnixMilli() - game.pausedAt
+	game.matchStartTime += pausedFor
+
+	log.Printf("[MP #%v] Match paused by #%v was resumed by #%v after %vms\n", game.Data.Id, game.pausedById, resumedBy.Info.Id, pausedFor)
+	game.clearPause()
+
+	game.sendBotMessage(fmt.Sprintf("The match has been resumed by %v.", resumedBy.Info.Username))
+	game.sendPacketToPlayers(packets.NewServerGamePauseChanged(false, resumedBy.Info.Id))
+	return nil
+}
+
+// IsPaused Returns if the match in progress is paused
+func (game *Game) IsPaused() bool {
+	return game.pausedAt != 0
+}
+
+// Clears the pause, such as when the match ends while paused
+func (game *Game) clearPause() {
+	game.pausedAt = 0
+	game.pausedById = 0
+	game.pausedForReferee = false
+}
+
+// Returns the user making a request, or the bot if it was made by the server
+func getRequesterOrBot(requester *sessions.User) *sessions.User {
+	if requester == nil {
+		return chat.Bot
+	}
+
+	return requester
+}
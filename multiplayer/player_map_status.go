@@ -0,0 +1,30 @@
+package multiplayer
+
+import (
+	"example.com/Quaver/Z/sessions"
+)
+
+// PlayerMapStatus The map a player currently has selected, according to their live client status
+type PlayerMapStatus struct {
+	UserId int
+	MapMd5 string
+	Loaded bool // If the player is on the game's map
+}
+
+// GetPlayerMapStatuses Returns which map each player in the game is currently on, and if it matches the game's map
+func (game *Game) GetPlayerMapStatuses() []PlayerMapStatus {
+	statuses := make([]PlayerMapStatus, 0, len(game.Data.PlayerIds))
+
+	for _, id := range game.Data.PlayerIds {
+		status := PlayerMapStatus{UserId: id}
+
+		if user := sessions.GetUserById(id); user != nil {
+			status.MapMd5 = user.GetClientStatus().MapMd5
+		}
+
+		status.Loaded = status.MapMd5 != "" && status.MapMd5 == game.Data.MapMD5
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
@@ -0,0 +1,114 @@
+package multiplayer
+
+import (
+	"errors"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+	"fmt"
+	"time"
+)
+
+// BeginReadyCheck Gives players a limited amount of time to ready up before the match starts.
+// Once it runs out, players who aren't ready are either left out of the match or the start is cancelled.
+func (game *Game) BeginReadyCheck(requester *sessions.User, timeout time.Duration) error {
+	if game.Data.InProgress {
+		return errors.New("the match is already in progress")
+	}
+
+	if !game.isUserHost(requester) {
+		return errors.New("only the host is able to start a ready check")
+	}
+
+	if game.readyCheckTimer != nil {
+		return errors.New("a ready check is already in progress")
+	}
+
+	if timeout <= 0 {
+		return errors.New("the ready check must last longer than zero seconds")
+	}
+
+	game.readyCheckEndTime = time.Now().Add(timeout).UnixMilli()
+	game.readyCheckTimer = time.AfterFunc(timeout, func() {
+		game.RunLocked(func() {
+			game.finishReadyCheck()
+		})
+	})
+
+	game.sendBotMessage(fmt.Sprintf("A ready check has started. Players have %v seconds to ready up.", int(timeout.Seconds())))
+	game.sendPacketToPlayers(packets.NewServerGameReadyCheck(game.readyCheckEndTime))
+	return nil
+}
+
+// Ends the live ready check and starts the match if possible. Returns the players who failed to ready up.
+func (game *Game) finishReadyCheck() []int {
+	// The check may have been cleared while the timer was waiting on the lock
+	if game.readyCheckTimer == nil || game.Data.InProgress {
+		return nil
+	}
+
+	game.readyCheckTimer.Stop()
+	game.readyCheckTimer = nil
+	game.readyCheckEndTime = 0
+
+	failed := game.getReadyCheckFailures()
+
+	if len(failed) > 0 && (getReadyCheckCancels() || len(failed) == len(game.getReadyCheckPlayers())) {
+		game.sendBotMessage("The match start has been cancelled because not every player readied up in time.")
+		game.sendPacketToPlayers(packets.NewServerGameReadyCheckEnded(failed, true))
+		return failed
+	}
+
+	if len(failed) > 0 {
+		game.sendBotMessage(fmt.Sprintf("%v player(s) didn't ready up in time and will sit out this match.", len(failed)))
+	}
+
+	game.playersExcluded = failed
+	game.sendPacketToPlayers(packets.NewServerGameReadyCheckEnded(failed, false))
+	game.StartGame()
+	return failed
+}
+
+// Stops the ready check if one is live
+func (game *Game) clearReadyCheck() {
+	game.playersExcluded = []int{}
+
+	if game.readyCheckTimer == nil {
+		return
+	}
+
+	game.readyCheckTimer.Stop()
+	game.readyCheckTimer = nil
+	game.readyCheckEndTime = 0
+
+	game.sendPacketToPlayers(packets.NewServerGameReadyCheckEnded([]int{}, true))
+}
+
+// Returns the players who are expected to ready up before the match can start
+func (game *Game) getReadyCheckPlayers() []int {
+	return utils.Filter(game.Data.PlayerIds, func(x int) bool {
+		return !game.isReferee(x) && !utils.Includes(game.Data.PlayersWithoutMap, x)
+	})
+}
+
+// Returns the players who are expected to ready up but haven't yet
+func (game *Game) getReadyCheckFailures() []int {
+	return utils.Filter(game.getReadyCheckPlayers(), func(x int) bool {
+		return !utils.Includes(game.Data.PlayersReady, x)
+	})
+}
+
+// Returns how long players have to ready up once the host starts the match. Zero if ready checks are disabled.
+func getReadyCheckTimeout() time.Duration {
+	if config.Instance == nil || config.Instance.Multiplayer.ReadyCheckTimeout <= 0 {
+		return 0
+	}
+
+	return time.Duration(config.Instance.Multiplayer.ReadyCheckTimeout) * time.Second
+}
+
+// Returns if failing the ready check should cancel the match start rather than leave the failed players out
+func getReadyCheckCancels() bool {
+	return config.Instance != nil && config.Instance.Multiplayer.ReadyCheckAction == "cancel"
+}
@@ -1,20 +1,24 @@
 package multiplayer
 
 import (
+	"context"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/scoring"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
-	"fmt"
+	"github.com/go-redis/redis/v8"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // ClearRedisGames Clears all cached multiplayer games in Redis (usually done once at server start)
 func ClearRedisGames() error {
-	err := db.ClearRedisKeysWithPattern("quaver:server:multiplayer:*")
+	err := db.ClearRedisKeysWithPattern(db.RedisKey("multiplayer", "*"))
 
 	if err != nil {
 		return err
@@ -23,13 +27,64 @@ func ClearRedisGames() error {
 	return err
 }
 
+// ClearOrphanedRedisPlayers Deletes cached multiplayer players whose game no longer has cached match settings,
+// which can be left behind after an unclean shutdown. Returns the amount of keys deleted.
+func ClearOrphanedRedisPlayers() (int, error) {
+	var cursor uint64
+	deleted := 0
+
+	for {
+		keys, next, err := db.Redis.Scan(db.RedisCtx, cursor, db.RedisKey("multiplayer", "*", "player", "*"), 1000).Result()
+
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, key := range keys {
+			// {prefix}multiplayer:{gameId}:player:{userId}
+			parts := strings.Split(strings.TrimPrefix(key, db.RedisKey("multiplayer", "")), ":")
+
+			if len(parts) != 3 {
+				continue
+			}
+
+			exists, err := db.Redis.Exists(db.RedisCtx, db.RedisKey("multiplayer", parts[0])).Result()
+
+			if err != nil {
+				return deleted, err
+			}
+
+			if exists != 0 {
+				continue
+			}
+
+			if err := db.Redis.Del(db.RedisCtx, key).Err(); err != nil {
+				return deleted, err
+			}
+
+			deleted++
+		}
+
+		cursor = next
+
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
 // Returns the redis key for the match settings
 func (game *Game) getMatchSettingsRedisKey() string {
-	return fmt.Sprintf("quaver:server:multiplayer:%v", game.Data.Id)
+	return db.RedisKey("multiplayer", strconv.Itoa(game.Data.Id))
 }
 
 // Caches the current match settings in redis
 func (game *Game) cacheMatchSettings() {
+	// Match state is only cached for external readers, so it can be skipped while redis is down.
+	if !db.IsRedisAvailable() {
+		return
+	}
+
 	settings := []string{
 		"n", game.Data.Name,
 		"pw", strconv.Itoa(utils.BoolToInt(game.Data.HasPassword)),
@@ -39,22 +94,42 @@ func (game *Game) cacheMatchSettings() {
 		"msid", strconv.Itoa(game.Data.MapsetId),
 		"map", game.Data.MapName,
 		"host", strconv.Itoa(game.Data.HostId),
+		"ref", strconv.Itoa(game.Data.RefereeId),
+		"refs", getIdListString(game.Data.RefereeIds),
 		"r", strconv.Itoa(int(game.Data.Ruleset)),
 		"hr", strconv.Itoa(utils.BoolToInt(game.Data.IsHostRotation)),
 		"gm", strconv.Itoa(int(game.Data.MapGameMode)),
 		"d", strconv.FormatFloat(game.Data.MapDifficultyRating, 'f', -1, 64),
 		"inp", strconv.Itoa(utils.BoolToInt(game.Data.InProgress)),
+		"st", strconv.Itoa(int(game.state)),
 		"m", strconv.FormatInt(int64(game.Data.GlobalModifiers), 10),
 		"fm", strconv.Itoa(int(game.Data.FreeModType)),
 		"trn", strconv.Itoa(utils.BoolToInt(game.Data.IsTournamentMode)),
+		"har", strconv.Itoa(utils.BoolToInt(game.Data.IsHostAutoReady)),
+		"sa", strconv.Itoa(int(game.Data.SpectatorAccess)),
+		"bo", strconv.Itoa(game.Data.BestOf),
+		"minfps", strconv.Itoa(game.Data.MinimumFps),
+		"scc", strconv.Itoa(utils.BoolToInt(game.Data.SpectatorsCanChat)),
+		"spc", strconv.Itoa(len(game.spectators)),
+		"mind", strconv.FormatFloat(float64(game.Data.FilterMinDifficultyRating), 'f', -1, 32),
+		"maxd", strconv.FormatFloat(float64(game.Data.FilterMaxDifficultyRating), 'f', -1, 32),
+		"ag", getAllowedGameModesString(game.Data.FilterAllowedGameModes),
 		// "t", strconv.Itoa(0), -  Game Type
-		// "h", strconv.Itoa(0), - Health Type
-		// "lv", strconv.Itoa(3) - Life Count
-		// "rtw", strconv.Itoa(game.Data.TeamRedWins), - Red Team Wins
-		// "btw", strconv.Itoa(game.DAta.TeamBlueWins), - Blue Team Wins
+		"h", strconv.Itoa(int(game.Data.HealthType)),
+		"lv", strconv.Itoa(game.Data.LifeCount),
+		"rtw", strconv.Itoa(game.Data.TeamRedWins),
+		"btw", strconv.Itoa(game.Data.TeamBlueWins),
 	}
 
-	_, err := db.Redis.HSet(db.RedisCtx, game.getMatchSettingsRedisKey(), settings).Result()
+	err := db.WithRetry(func() error {
+		_, err := db.Redis.Pipelined(db.RedisCtx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(db.RedisCtx, game.getMatchSettingsRedisKey(), settings)
+			pipe.Expire(db.RedisCtx, game.getMatchSettingsRedisKey(), getCachedGameTtl())
+			return nil
+		})
+
+		return err
+	})
 
 	if err != nil {
 		log.Printf("Failed to cache match settings in redis - %v\n", err)
@@ -62,27 +137,162 @@ func (game *Game) cacheMatchSettings() {
 	}
 }
 
-// Deletes the cached match settings in redis
-func (game *Game) deleteCachedMatchSettings() {
-	_, err := db.Redis.Del(db.RedisCtx, game.getMatchSettingsRedisKey()).Result()
+// StartCachedGameRefresher Starts a goroutine that keeps the redis keys of every live game from expiring,
+// even if nothing in the game changes for longer than the TTL
+func StartCachedGameRefresher(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(getCachedGameTtl() / 2)
+		defer ticker.Stop()
 
-	if err != nil {
-		log.Printf("Failed to remove match settings in redis - %v\n", err)
-		return
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refreshCachedGames(); err != nil {
+					log.Printf("Failed to refresh cached multiplayer games in redis - %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Extends the expiry of every live game's match settings and players in a single pipeline
+func refreshCachedGames() error {
+	if !db.IsRedisAvailable() {
+		return nil
+	}
+
+	pipe := db.Redis.Pipeline()
+
+	for _, game := range GetGames() {
+		game.RunLocked(func() {
+			game.expireCachedKeys(pipe)
+		})
 	}
+
+	if pipe.Len() == 0 {
+		return nil
+	}
+
+	_, err := pipe.Exec(db.RedisCtx)
+	return err
+}
+
+// Queues the expiry of the game's match settings and players onto a pipeline
+func (game *Game) expireCachedKeys(pipe redis.Pipeliner) {
+	ttl := getCachedGameTtl()
+	pipe.Expire(db.RedisCtx, game.getMatchSettingsRedisKey(), ttl)
+
+	for _, id := range game.Data.PlayerIds {
+		pipe.Expire(db.RedisCtx, game.getPlayerRedisKey(id), ttl)
+	}
+}
+
+// Returns how long cached games live in redis without being refreshed
+func getCachedGameTtl() time.Duration {
+	if config.Instance == nil || config.Instance.Multiplayer.CachedGameTtl <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(config.Instance.Multiplayer.CachedGameTtl) * time.Second
+}
+
+// Returns the allowed game modes as a comma separated list for caching
+func getAllowedGameModesString(modes []common.Mode) string {
+	strs := make([]string, 0, len(modes))
+
+	for _, mode := range modes {
+		strs = append(strs, strconv.Itoa(int(mode)))
+	}
+
+	return strings.Join(strs, ",")
+}
+
+// Returns the user ids as a comma separated list for caching
+func getIdListString(ids []int) string {
+	strs := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		strs = append(strs, strconv.Itoa(id))
+	}
+
+	return strings.Join(strs, ",")
 }
 
 // Returns the redis key for an individual user in the game
 func (game *Game) getPlayerRedisKey(id int) string {
-	return fmt.Sprintf("quaver:server:multiplayer:%v:player:%v", game.Data.Id, id)
+	return db.RedisKey("multiplayer", strconv.Itoa(game.Data.Id), "player", strconv.Itoa(id))
 }
 
 // Caches a player in Redis
 func (game *Game) cachePlayer(id int) {
+	if !db.IsRedisAvailable() {
+		return
+	}
+
+	player := game.getCachedPlayerFields(id)
+
+	if player == nil {
+		return
+	}
+
+	// Any change to a player also keeps the rest of the game's keys alive
+	err := db.WithRetry(func() error {
+		_, err := db.Redis.Pipelined(db.RedisCtx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(db.RedisCtx, game.getPlayerRedisKey(id), player)
+			game.expireCachedKeys(pipe)
+			return nil
+		})
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Failed to cache multiplayer player in redis - %v\n", err)
+		return
+	}
+}
+
+// Caches every player in the game in a single pipeline. Caching a full lobby of 16 players takes 1 round trip
+// to redis, where calling cachePlayer for each of them takes 16 (see TestCachedPlayerRoundTrips).
+func (game *Game) cacheAllPlayers() {
+	if !db.IsRedisAvailable() {
+		return
+	}
+
+	players := map[int][]string{}
+
+	for _, id := range game.Data.PlayerIds {
+		if player := game.getCachedPlayerFields(id); player != nil {
+			players[id] = player
+		}
+	}
+
+	err := db.WithRetry(func() error {
+		_, err := db.Redis.Pipelined(db.RedisCtx, func(pipe redis.Pipeliner) error {
+			for id, player := range players {
+				pipe.HSet(db.RedisCtx, game.getPlayerRedisKey(id), player)
+			}
+
+			game.expireCachedKeys(pipe)
+			return nil
+		})
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Failed to cache multiplayer players in redis - %v\n", err)
+	}
+}
+
+// Returns the fields of a player that are cached in redis, or nil if the player is offline
+func (game *Game) getCachedPlayerFields(id int) []string {
 	user := sessions.GetUserById(id)
 
 	if user == nil {
-		return
+		return nil
 	}
 
 	wins, err := utils.Find(game.Data.PlayerWins, func(x *objects.MultiplayerGamePlayerWins) bool { return x.Id == id })
@@ -97,7 +307,9 @@ func (game *Game) cachePlayer(id int) {
 		mods = &objects.MultiplayerGamePlayerMods{Modifiers: 0}
 	}
 
-	player := []string{
+	team, _ := game.GetPlayerTeam(id)
+
+	return []string{
 		"id", strconv.Itoa(user.Info.Id),
 		"u", user.Info.Username,
 		"sid", user.Info.SteamId,
@@ -107,14 +319,8 @@ func (game *Game) cachePlayer(id int) {
 		"m", strconv.Itoa(int(mods.Modifiers)),
 		"r", strconv.Itoa(utils.BoolToInt(utils.Includes(game.Data.PlayersReady, id))),
 		"hm", strconv.Itoa(utils.BoolToInt(!utils.Includes(game.Data.PlayersWithoutMap, id))),
-		// "t", strconv.Itoa(0) - Team
-	}
-
-	_, err = db.Redis.HSet(db.RedisCtx, game.getPlayerRedisKey(id), player).Result()
-
-	if err != nil {
-		log.Printf("Failed to cache multiplayer player in redis - %v\n", err)
-		return
+		"dp", strconv.Itoa(game.GetPlayerDownloadProgress(id)),
+		"t", strconv.Itoa(int(team)),
 	}
 }
 
@@ -128,13 +334,44 @@ func (game *Game) deleteCachedPlayer(userId int) {
 	}
 }
 
+// Deletes the cached match settings along with the given players and their scores in a single pipeline.
+// Used when the game is disbanded. Deleting a game with 16 players takes 1 round trip to redis, instead of 1 for
+// the settings and 16 more for deleteCachedPlayer (see TestCachedPlayerRoundTrips).
+func (game *Game) deleteCachedGame(playerIds []int) {
+	if !db.IsRedisAvailable() {
+		return
+	}
+
+	err := db.WithRetry(func() error {
+		_, err := db.Redis.Pipelined(db.RedisCtx, func(pipe redis.Pipeliner) error {
+			pipe.Del(db.RedisCtx, game.getMatchSettingsRedisKey())
+
+			for _, id := range playerIds {
+				pipe.Del(db.RedisCtx, game.getPlayerRedisKey(id), game.getPlayerScoreRedisKey(id))
+			}
+
+			return nil
+		})
+
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Failed to remove multiplayer game in redis - %v\n", err)
+	}
+}
+
 // Returns the redis key for a player's score in redis.
 func (game *Game) getPlayerScoreRedisKey(userId int) string {
-	return fmt.Sprintf("quaver:server:multiplayer:%v:%v", game.Data.GameId, userId)
+	return db.RedisKey("multiplayer", game.Data.GameId, strconv.Itoa(userId))
 }
 
 // Caches a player's score in redis.
 func (game *Game) cachePlayerScore(userId int, processor *scoring.ScoreProcessor) {
+	if !db.IsRedisAvailable() {
+		return
+	}
+
 	player := []string{
 		"m", strconv.FormatInt(int64(processor.Modifiers), 10),
 		"pr", strconv.FormatFloat(processor.PerformanceRating, 'f', -1, 64),
@@ -149,14 +386,18 @@ func (game *Game) cachePlayerScore(userId int, processor *scoring.ScoreProcessor
 		"cm", strconv.Itoa(processor.Combo),
 		// "t", "0", - Team
 		// "sc", "0", - Score
-		// "hl", strconv.Itoa(100), - Health
 		// "fc", "0" - Boolean for full combo
-		// "lv" - Lives
 		// "hf" - Has Failed
 		// "rh" - Is Regenerating Health
 		// "br" - Battle Royale Rank
 	}
 
+	if health, ok := game.getPlayerHealth(userId); ok {
+		player = append(player,
+			"hl", strconv.FormatFloat(health.Health, 'f', -1, 64),
+			"lv", strconv.Itoa(health.Lives))
+	}
+
 	_, err := db.Redis.HSet(db.RedisCtx, game.getPlayerScoreRedisKey(userId), player).Result()
 
 	if err != nil {
@@ -0,0 +1,8 @@
+package multiplayer
+
+// ScoreOverride The values a referee replaces a player's submitted score with
+type ScoreOverride struct {
+	PerformanceRating float64
+	Accuracy          float64
+	MaxCombo          int
+}
@@ -0,0 +1,95 @@
+package multiplayer
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// GetScoreboard Returns the live scores of every player in the current match, ordered by placement
+func (game *Game) GetScoreboard() []*objects.MultiplayerGameScoreboardEntry {
+	scores := make([]*objects.MultiplayerGameScoreboardEntry, 0, len(game.playerScores))
+
+	for userId, score := range game.playerScores {
+		scores = append(scores, &objects.MultiplayerGameScoreboardEntry{
+			Id:                userId,
+			PerformanceRating: score.PerformanceRating,
+			Accuracy:          score.Accuracy,
+			Combo:             score.Combo,
+			MaxCombo:          score.MaxCombo,
+		})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].PerformanceRating == scores[j].PerformanceRating {
+			return scores[i].Id < scores[j].Id
+		}
+
+		return scores[i].PerformanceRating > scores[j].PerformanceRating
+	})
+
+	for i, score := range scores {
+		score.Placement = i + 1
+	}
+
+	return scores
+}
+
+// Sends the live scoreboard to the referee and tournament spectators, and publishes it to redis if enabled.
+// Live updates are throttled to the configured scoreboard interval, while the final results are always sent.
+func (game *Game) broadcastScoreboard(finished bool) {
+	if !finished && time.Now().UnixMilli()-game.lastScoreboardBroadcast < getScoreboardInterval() {
+		return
+	}
+
+	game.lastScoreboardBroadcast = time.Now().UnixMilli()
+	packet := packets.NewServerGameScoreboard(game.Data.GameId, finished, game.GetScoreboard())
+
+	for _, id := range game.spectators {
+		user := sessions.GetUserById(id)
+
+		if user == nil {
+			continue
+		}
+
+		if !game.isReferee(id) && !common.HasPrivilege(user.Info.Privileges, common.PrivilegeEnableTournamentMode) {
+			continue
+		}
+
+		sessions.SendPacketToUser(packet, user)
+	}
+
+	if config.Instance == nil || !config.Instance.Multiplayer.PublishScoreboard || !db.IsRedisAvailable() {
+		return
+	}
+
+	data, err := json.Marshal(packet)
+
+	if err != nil {
+		log.Printf("[MP #%v] Failed to marshal scoreboard - %v\n", game.Data.Id, err)
+		return
+	}
+
+	err = db.Redis.Publish(db.RedisCtx, db.RedisChannelMultiplayerScoreboard, data).Err()
+
+	if err != nil {
+		log.Printf("[MP #%v] Failed to publish scoreboard - %v\n", game.Data.Id, err)
+	}
+}
+
+// Returns the minimum amount of milliseconds between live scoreboard updates
+func getScoreboardInterval() int64 {
+	if config.Instance == nil || config.Instance.Multiplayer.ScoreboardInterval <= 0 {
+		return 1000
+	}
+
+	return int64(config.Instance.Multiplayer.ScoreboardInterval)
+}
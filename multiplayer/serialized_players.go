@@ -0,0 +1,48 @@
+package multiplayer
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/sessions"
+	"fmt"
+	"strings"
+)
+
+// GetSerializedPlayers Returns the players in the game serialized for a packet. If enabled in the config,
+// players whose names look the same have their user id appended, so hosts are able to tell them apart.
+func (game *Game) GetSerializedPlayers() []*objects.PacketUser {
+	players := make([]*objects.PacketUser, 0, len(game.Data.PlayerIds))
+
+	for _, id := range game.Data.PlayerIds {
+		if player := sessions.GetUserById(id); player != nil {
+			players = append(players, player.SerializeForPacket())
+		}
+	}
+
+	if config.Instance != nil && config.Instance.Multiplayer.DisambiguateUsernames {
+		disambiguateUsernames(players)
+	}
+
+	return players
+}
+
+// Appends the user id to every player whose name matches another player's, ignoring case and surrounding spaces.
+// The players are expected to be freshly serialized, so the usernames on the sessions are left untouched.
+func disambiguateUsernames(players []*objects.PacketUser) {
+	counts := map[string]int{}
+
+	for _, player := range players {
+		counts[normalizeUsername(player.Username)]++
+	}
+
+	for _, player := range players {
+		if counts[normalizeUsername(player.Username)] > 1 {
+			player.Username = fmt.Sprintf("%v (#%v)", player.Username, player.Id)
+		}
+	}
+}
+
+// Returns a username in the form that is used to compare it with others
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
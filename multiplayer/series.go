@@ -0,0 +1,76 @@
+package multiplayer
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+)
+
+// Returns the amount of match wins needed to win the series
+func (game *Game) getSeriesWinsNeeded() int {
+	return game.Data.BestOf/2 + 1
+}
+
+// Checks if a player or team has won enough matches to win the series. If so, the result is broadcasted
+// and the win counts are reset for the next series.
+func (game *Game) checkSeriesCompletion() {
+	if game.Data.BestOf <= 0 {
+		return
+	}
+
+	winsNeeded := game.getSeriesWinsNeeded()
+	var winnerIds []int
+	var winnerName string
+
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetTeam {
+		switch {
+		case game.Data.TeamRedWins >= winsNeeded:
+			winnerIds, winnerName = game.Data.PlayersRedTeam, "Red Team"
+		case game.Data.TeamBlueWins >= winsNeeded:
+			winnerIds, winnerName = game.Data.PlayersBlueTeam, "Blue Team"
+		}
+	} else {
+		for _, playerWins := range game.Data.PlayerWins {
+			if playerWins.Wins < winsNeeded {
+				continue
+			}
+
+			winnerIds = []int{playerWins.Id}
+
+			if user := sessions.GetUserById(playerWins.Id); user != nil {
+				winnerName = user.Info.Username
+			} else {
+				winnerName = fmt.Sprintf("Player #%v", playerWins.Id)
+			}
+
+			break
+		}
+	}
+
+	if winnerIds == nil {
+		return
+	}
+
+	game.sendBotMessage(fmt.Sprintf("%v has won the best of %v series! (%v)", winnerName, game.Data.BestOf, game.getSeriesScoreline()))
+	game.sendPacketToPlayers(packets.NewServerGameSeriesResult(winnerIds))
+
+	game.resetWins()
+}
+
+// Returns the current win counts of the series
+func (game *Game) getSeriesScoreline() string {
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetTeam {
+		return fmt.Sprintf("%v - %v", game.Data.TeamRedWins, game.Data.TeamBlueWins)
+	}
+
+	wins := make([]string, 0, len(game.Data.PlayerWins))
+
+	for _, playerWins := range game.Data.PlayerWins {
+		wins = append(wins, fmt.Sprintf("%v", playerWins.Wins))
+	}
+
+	return strings.Join(wins, " - ")
+}
@@ -0,0 +1,52 @@
+package multiplayer
+
+import (
+	"sync"
+
+	"example.com/Quaver/Z/config"
+)
+
+var (
+	// The total amount of spectators across every multiplayer game
+	totalSpectators int
+
+	// Mutex for totalSpectators
+	totalSpectatorsMutex = &sync.Mutex{}
+)
+
+// GetTotalSpectatorCount Returns the amount of spectators across every multiplayer game
+func GetTotalSpectatorCount() int {
+	totalSpectatorsMutex.Lock()
+	defer totalSpectatorsMutex.Unlock()
+
+	return totalSpectators
+}
+
+// Adds a spectator to the game if it doesn't go over the server-wide spectator cap. The check and the count are
+// updated under the same lock, so concurrent joins can't both take the last place.
+func (game *Game) tryAddSpectator(userId int) bool {
+	totalSpectatorsMutex.Lock()
+
+	if config.Instance != nil && config.Instance.Multiplayer.MaxTotalSpectators > 0 &&
+		totalSpectators >= config.Instance.Multiplayer.MaxTotalSpectators {
+		totalSpectatorsMutex.Unlock()
+		return false
+	}
+
+	totalSpectators++
+	totalSpectatorsMutex.Unlock()
+
+	game.spectators = append(game.spectators, userId)
+	game.updateChatPermissions()
+	return true
+}
+
+// Replaces the game's spectators, keeping the server-wide spectator count in sync
+func (game *Game) setSpectators(spectators []int) {
+	totalSpectatorsMutex.Lock()
+	totalSpectators += len(spectators) - len(game.spectators)
+	totalSpectatorsMutex.Unlock()
+
+	game.spectators = spectators
+	game.updateChatPermissions()
+}
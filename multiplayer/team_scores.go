@@ -0,0 +1,57 @@
+package multiplayer
+
+import (
+	"time"
+
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+)
+
+// The minimum amount of milliseconds between live team score broadcasts
+const teamScoresBroadcastInterval int64 = 1000
+
+// TeamScores The summed performance rating of each team in the current match
+type TeamScores struct {
+	Red  float64
+	Blue float64
+}
+
+// LiveTeamScores Sums the current scores of each team. Players who haven't submitted a score yet count as zero.
+func (game *Game) LiveTeamScores() TeamScores {
+	scores := TeamScores{}
+
+	for _, id := range game.Data.PlayersRedTeam {
+		if score, ok := game.playerScores[id]; ok {
+			scores.Red += score.PerformanceRating
+		}
+	}
+
+	for _, id := range game.Data.PlayersBlueTeam {
+		if score, ok := game.playerScores[id]; ok {
+			scores.Blue += score.PerformanceRating
+		}
+	}
+
+	return scores
+}
+
+// GetLastTeamScores Returns the team totals from the most recent broadcast, which are kept after the match ends
+func (game *Game) GetLastTeamScores() TeamScores {
+	return game.lastTeamScores
+}
+
+// Computes and broadcasts the running team totals. Unless forced, broadcasts are throttled.
+func (game *Game) broadcastTeamScores(force bool) {
+	if game.Data.Ruleset != objects.MultiplayerGameRulesetTeam {
+		return
+	}
+
+	game.lastTeamScores = game.LiveTeamScores()
+
+	if !force && time.Now().UnixMilli()-game.lastTeamScoresBroadcast < teamScoresBroadcastInterval {
+		return
+	}
+
+	game.lastTeamScoresBroadcast = time.Now().UnixMilli()
+	game.sendPacketToPlayers(packets.NewServerGameTeamScores(game.lastTeamScores.Red, game.lastTeamScores.Blue))
+}
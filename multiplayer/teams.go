@@ -0,0 +1,211 @@
+package multiplayer
+
+import (
+	"fmt"
+
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/sessions"
+	"example.com/Quaver/Z/utils"
+)
+
+// GetPlayerTeam Returns the team a player is on, and false if they aren't on either team
+func (game *Game) GetPlayerTeam(userId int) (objects.MultiplayerTeam, bool) {
+	switch {
+	case utils.Includes(game.Data.PlayersRedTeam, userId):
+		return objects.MultiplayerTeamRed, true
+	case utils.Includes(game.Data.PlayersBlueTeam, userId):
+		return objects.MultiplayerTeamBlue, true
+	default:
+		return objects.MultiplayerTeamRed, false
+	}
+}
+
+// ChangePlayerTeam Moves a player onto a team. Players can switch their own team, while the host can move anyone.
+// In team games, the teams can't be left more than one player apart.
+func (game *Game) ChangePlayerTeam(requester *sessions.User, userId int, team objects.MultiplayerTeam) {
+	if requester != nil && requester.Info.Id != userId && !game.isUserHost(requester) {
+		return
+	}
+
+	if !utils.Includes(game.Data.PlayerIds, userId) {
+		return
+	}
+
+	if team != objects.MultiplayerTeamRed && team != objects.MultiplayerTeamBlue {
+		return
+	}
+
+	if current, ok := game.GetPlayerTeam(userId); ok && current == team {
+		return
+	}
+
+	if game.Data.InProgress {
+		notifyTeamChangeFailed(requester, "You cannot change teams while the match is in progress.")
+		return
+	}
+
+	if game.Data.Ruleset == objects.MultiplayerGameRulesetTeam {
+		red, blue := game.getTeamSizesWithout(userId)
+
+		if !isTeamChangeBalanced(red, blue, team) {
+			notifyTeamChangeFailed(requester, fmt.Sprintf("Unable to join the %v, as the teams would be uneven.", getTeamName(team)))
+			return
+		}
+	}
+
+	game.setPlayerTeam(userId, team)
+	game.cachePlayer(userId)
+
+	if user := sessions.GetUserById(userId); user != nil {
+		game.sendBotMessage(fmt.Sprintf("%v has joined the %v.", user.Info.Username, getTeamName(team)))
+	}
+
+	game.sendPacketToPlayers(packets.NewServerGamePlayerTeamChanged(userId, team))
+	sendLobbyUsersGameInfoPacket(game, true)
+}
+
+// ResetTeamWins Zeroes the win counts of both teams, such as after the teams have been rebalanced
+func (game *Game) ResetTeamWins() {
+	game.Data.TeamRedWins = 0
+	game.Data.TeamBlueWins = 0
+
+	game.cacheMatchSettings()
+	game.sendPacketToPlayers(packets.NewServerGameTeamWinCount(0, 0))
+}
+
+// Adds a win to a team's win count
+func (game *Game) addTeamWin(team objects.MultiplayerTeam) {
+	switch team {
+	case objects.MultiplayerTeamRed:
+		game.Data.TeamRedWins++
+	case objects.MultiplayerTeamBlue:
+		game.Data.TeamBlueWins++
+	}
+}
+
+// Returns the team with the higher total score in a match, and false if the match was a draw
+func getTeamMatchWinner(scores TeamScores) (objects.MultiplayerTeam, bool) {
+	switch {
+	case scores.Red > scores.Blue:
+		return objects.MultiplayerTeamRed, true
+	case scores.Blue > scores.Red:
+		return objects.MultiplayerTeamBlue, true
+	default:
+		return objects.MultiplayerTeamRed, false
+	}
+}
+
+// Tells the requester why their team change was refused. Nothing is sent for changes made by the server.
+func notifyTeamChangeFailed(requester *sessions.User, message string) {
+	if requester == nil {
+		return
+	}
+
+	sessions.SendPacketToUser(packets.NewServerNotificationError(message), requester)
+}
+
+// Puts players without a team on one, then moves players off the larger team until both teams are within
+// one player of each other. Players who are moved are told about their new team.
+func (game *Game) balanceTeams() {
+	changed := make([]int, 0)
+
+	for _, id := range game.Data.PlayerIds {
+		if _, ok := game.GetPlayerTeam(id); !ok {
+			game.assignTeam(id)
+			changed = append(changed, id)
+		}
+	}
+
+	for {
+		red, blue := len(game.Data.PlayersRedTeam), len(game.Data.PlayersBlueTeam)
+
+		if red-blue > 1 {
+			id := game.Data.PlayersRedTeam[red-1]
+			game.setPlayerTeam(id, objects.MultiplayerTeamBlue)
+			changed = append(changed, id)
+		} else if blue-red > 1 {
+			id := game.Data.PlayersBlueTeam[blue-1]
+			game.setPlayerTeam(id, objects.MultiplayerTeamRed)
+			changed = append(changed, id)
+		} else {
+			break
+		}
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	game.cacheAllPlayers()
+
+	for _, id := range changed {
+		team, _ := game.GetPlayerTeam(id)
+		game.sendPacketToPlayers(packets.NewServerGamePlayerTeamChanged(id, team))
+	}
+}
+
+// Places a player who just joined the game onto the team with fewer players. Ties go to the red team.
+func (game *Game) assignTeam(userId int) objects.MultiplayerTeam {
+	red, blue := game.getTeamSizesWithout(userId)
+	team := objects.MultiplayerTeamRed
+
+	if blue < red {
+		team = objects.MultiplayerTeamBlue
+	}
+
+	game.setPlayerTeam(userId, team)
+	return team
+}
+
+// Puts a player on a team, taking them off the other one
+func (game *Game) setPlayerTeam(userId int, team objects.MultiplayerTeam) {
+	game.removePlayerFromTeams(userId)
+
+	switch team {
+	case objects.MultiplayerTeamRed:
+		game.Data.PlayersRedTeam = append(game.Data.PlayersRedTeam, userId)
+	case objects.MultiplayerTeamBlue:
+		game.Data.PlayersBlueTeam = append(game.Data.PlayersBlueTeam, userId)
+	}
+}
+
+// Takes a player off both teams
+func (game *Game) removePlayerFromTeams(userId int) {
+	game.Data.PlayersRedTeam = utils.Filter(game.Data.PlayersRedTeam, func(x int) bool { return x != userId })
+	game.Data.PlayersBlueTeam = utils.Filter(game.Data.PlayersBlueTeam, func(x int) bool { return x != userId })
+}
+
+// Returns the size of each team, leaving out the given player
+func (game *Game) getTeamSizesWithout(userId int) (red int, blue int) {
+	red = len(utils.Filter(game.Data.PlayersRedTeam, func(x int) bool { return x != userId }))
+	blue = len(utils.Filter(game.Data.PlayersBlueTeam, func(x int) bool { return x != userId }))
+	return red, blue
+}
+
+// Returns if a player joining a team keeps both teams within one player of each other.
+// The team sizes shouldn't include the player that is changing teams.
+func isTeamChangeBalanced(red int, blue int, team objects.MultiplayerTeam) bool {
+	if team == objects.MultiplayerTeamRed {
+		red++
+	} else {
+		blue++
+	}
+
+	difference := red - blue
+
+	if difference < 0 {
+		difference = -difference
+	}
+
+	return difference <= 1
+}
+
+// Returns a readable name for a team
+func getTeamName(team objects.MultiplayerTeam) string {
+	if team == objects.MultiplayerTeamBlue {
+		return "Blue Team"
+	}
+
+	return "Red Team"
+}
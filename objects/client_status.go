@@ -14,6 +14,7 @@ const (
 	ClientStatusInLobby
 	ClientStatusMultiplayer
 	ClientStatusListening
+	ClientStatusAway
 )
 
 type ClientStatus struct {
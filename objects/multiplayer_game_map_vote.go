@@ -0,0 +1,8 @@
+package objects
+
+type MultiplayerGameMapVote struct {
+	MD5   string `json:"md5"`
+	MapId int    `json:"mid"`
+	Name  string `json:"map"`
+	Votes int    `json:"v"`
+}
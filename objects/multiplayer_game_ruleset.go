@@ -5,4 +5,5 @@ type MultiplayerGameRuleset int
 const (
 	MultiplayerGameRulesetFreeForAll MultiplayerGameRuleset = iota
 	MultiplayerGameRulesetTeam
+	MultiplayerGameRulesetBattleRoyale
 )
@@ -0,0 +1,10 @@
+package objects
+
+type MultiplayerGameScoreboardEntry struct {
+	Id                int     `json:"uid"`
+	PerformanceRating float64 `json:"pr"`
+	Accuracy          float64 `json:"a"`
+	Combo             int     `json:"cm"`
+	MaxCombo          int     `json:"mc"`
+	Placement         int     `json:"p"`
+}
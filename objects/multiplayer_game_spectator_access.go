@@ -0,0 +1,9 @@
+package objects
+
+type MultiplayerGameSpectatorAccess int
+
+const (
+	MultiplayerGameSpectatorAccessPassword MultiplayerGameSpectatorAccess = iota // Private games require the password or an invite to spectate
+	MultiplayerGameSpectatorAccessInvite                                         // Private games require an invite to spectate
+	MultiplayerGameSpectatorAccessOpen                                           // Anyone is able to spectate private games
+)
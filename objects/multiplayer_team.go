@@ -0,0 +1,8 @@
+package objects
+
+type MultiplayerTeam int
+
+const (
+	MultiplayerTeamRed MultiplayerTeam = iota
+	MultiplayerTeamBlue
+)
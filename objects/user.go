@@ -11,4 +11,7 @@ type PacketUser struct {
 	UserGroups  common.UserGroups `json:"ug"`
 	MuteEndTime int64             `json:"m"`
 	Country     string            `json:"c"`
+	GroupNames  []string          `json:"ugn,omitempty"` // Readable names of UserGroups. Only sent if enabled in the config.
+	MuteReason  string            `json:"mr,omitempty"`  // Why the user was muted. Only sent to staff.
+	MutedById   int               `json:"mb,omitempty"`  // The id of the user who muted them. Only sent to staff.
 }
@@ -0,0 +1,8 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ClientGameChangeHealthType struct {
+	Packet
+	HealthType objects.MultiplayerGameHealth `json:"ht"`
+}
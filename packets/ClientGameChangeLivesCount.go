@@ -0,0 +1,6 @@
+package packets
+
+type ClientGameChangeLivesCount struct {
+	Packet
+	Lives int `json:"lc"`
+}
@@ -0,0 +1,9 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ClientGameChangeOtherPlayerTeam struct {
+	Packet
+	UserId int                     `json:"u"`
+	Team   objects.MultiplayerTeam `json:"t"`
+}
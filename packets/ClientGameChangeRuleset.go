@@ -0,0 +1,8 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ClientGameChangeRuleset struct {
+	Packet
+	Ruleset objects.MultiplayerGameRuleset `json:"r"`
+}
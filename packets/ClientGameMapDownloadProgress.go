@@ -0,0 +1,6 @@
+package packets
+
+type ClientGameMapDownloadProgress struct {
+	Packet
+	Progress int `json:"p"`
+}
@@ -0,0 +1,8 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ClientGamePlayerTeamChanged struct {
+	Packet
+	Team objects.MultiplayerTeam `json:"t"`
+}
@@ -0,0 +1,6 @@
+package packets
+
+type ClientPerformanceReport struct {
+	Packet
+	Fps float64 `json:"fps"`
+}
@@ -0,0 +1,5 @@
+package packets
+
+type ClientRequestMatchSettings struct {
+	Packet
+}
@@ -0,0 +1,5 @@
+package packets
+
+type ClientRequestSessionInfo struct {
+	Packet
+}
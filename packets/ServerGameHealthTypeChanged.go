@@ -0,0 +1,15 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerGameHealthTypeChanged struct {
+	Packet
+	HealthType objects.MultiplayerGameHealth `json:"ht"`
+}
+
+func NewServerGameHealthTypeChanged(healthType objects.MultiplayerGameHealth) *ServerGameHealthTypeChanged {
+	return &ServerGameHealthTypeChanged{
+		Packet:     Packet{Id: PacketIdServerGameHealthTypeChanged},
+		HealthType: healthType,
+	}
+}
@@ -1,6 +1,10 @@
 package packets
 
-import "example.com/Quaver/Z/common"
+import (
+	"bytes"
+	"encoding/binary"
+	"example.com/Quaver/Z/common"
+)
 
 type ServerGameJudgements struct {
 	Packet
@@ -15,3 +19,19 @@ func NewServerGameJudgements(userId int, judgements []common.Judgements) *Server
 		Judgements: judgements,
 	}
 }
+
+// MarshalBinaryPacket Encodes the packet as its id (uint16), the user id (int32), the judgement count (uint16)
+// and then a byte per judgement, all little-endian.
+func (p *ServerGameJudgements) MarshalBinaryPacket() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 8+len(p.Judgements)))
+
+	_ = binary.Write(buf, binary.LittleEndian, uint16(p.Id))
+	_ = binary.Write(buf, binary.LittleEndian, int32(p.UserId))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(p.Judgements)))
+
+	for _, judgement := range p.Judgements {
+		buf.WriteByte(byte(judgement))
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,13 @@
+package packets
+
+type ServerGameLivesChanged struct {
+	Packet
+	Lives int `json:"lc"`
+}
+
+func NewServerGameLivesChanged(lives int) *ServerGameLivesChanged {
+	return &ServerGameLivesChanged{
+		Packet: Packet{Id: PacketIdServerGameLivesChanged},
+		Lives:  lives,
+	}
+}
@@ -0,0 +1,15 @@
+package packets
+
+type ServerGameMapDownloadProgress struct {
+	Packet
+	UserId   int `json:"uid"`
+	Progress int `json:"p"`
+}
+
+func NewServerGameMapDownloadProgress(userId int, progress int) *ServerGameMapDownloadProgress {
+	return &ServerGameMapDownloadProgress{
+		Packet:   Packet{Id: PacketIdServerGameMapDownloadProgress},
+		UserId:   userId,
+		Progress: progress,
+	}
+}
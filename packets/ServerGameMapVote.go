@@ -0,0 +1,17 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerGameMapVote struct {
+	Packet
+	EndTime int64                             `json:"t"`
+	Maps    []*objects.MultiplayerGameMapVote `json:"maps"`
+}
+
+func NewServerGameMapVote(endTime int64, maps []*objects.MultiplayerGameMapVote) *ServerGameMapVote {
+	return &ServerGameMapVote{
+		Packet:  Packet{Id: PacketIdServerGameMapVote},
+		EndTime: endTime,
+		Maps:    maps,
+	}
+}
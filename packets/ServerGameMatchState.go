@@ -0,0 +1,21 @@
+package packets
+
+type ServerGameMatchState struct {
+	Packet
+	InProgress       bool  `json:"ip"`
+	StartTime        int64 `json:"st"`
+	CountdownEndTime int64 `json:"ct"`
+	PlayersInMatch   []int `json:"pim"`
+	PlayersFinished  []int `json:"pf"`
+}
+
+func NewServerGameMatchState(inProgress bool, startTime int64, countdownEndTime int64, playersInMatch []int, playersFinished []int) *ServerGameMatchState {
+	return &ServerGameMatchState{
+		Packet:           Packet{Id: PacketIdServerGameMatchState},
+		InProgress:       inProgress,
+		StartTime:        startTime,
+		CountdownEndTime: countdownEndTime,
+		PlayersInMatch:   playersInMatch,
+		PlayersFinished:  playersFinished,
+	}
+}
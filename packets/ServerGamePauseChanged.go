@@ -0,0 +1,15 @@
+package packets
+
+type ServerGamePauseChanged struct {
+	Packet
+	Paused bool `json:"p"`
+	UserId int  `json:"uid"`
+}
+
+func NewServerGamePauseChanged(paused bool, userId int) *ServerGamePauseChanged {
+	return &ServerGamePauseChanged{
+		Packet: Packet{Id: PacketIdServerGamePauseChanged},
+		Paused: paused,
+		UserId: userId,
+	}
+}
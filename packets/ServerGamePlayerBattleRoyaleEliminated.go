@@ -0,0 +1,15 @@
+package packets
+
+type ServerGamePlayerBattleRoyaleEliminated struct {
+	Packet
+	UserId int `json:"u"`
+	Rank   int `json:"r"`
+}
+
+func NewServerGamePlayerBattleRoyaleEliminated(userId int, rank int) *ServerGamePlayerBattleRoyaleEliminated {
+	return &ServerGamePlayerBattleRoyaleEliminated{
+		Packet: Packet{Id: PacketIdServerGamePlayerBattleRoyaleEliminated},
+		UserId: userId,
+		Rank:   rank,
+	}
+}
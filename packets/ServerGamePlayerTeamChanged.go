@@ -0,0 +1,17 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerGamePlayerTeamChanged struct {
+	Packet
+	UserId int                     `json:"u"`
+	Team   objects.MultiplayerTeam `json:"t"`
+}
+
+func NewServerGamePlayerTeamChanged(userId int, team objects.MultiplayerTeam) *ServerGamePlayerTeamChanged {
+	return &ServerGamePlayerTeamChanged{
+		Packet: Packet{Id: PacketIdServerGamePlayerTeamChanged},
+		UserId: userId,
+		Team:   team,
+	}
+}
@@ -0,0 +1,13 @@
+package packets
+
+type ServerGameReadyCheck struct {
+	Packet
+	Timestamp int64 `json:"t"`
+}
+
+func NewServerGameReadyCheck(endTime int64) *ServerGameReadyCheck {
+	return &ServerGameReadyCheck{
+		Packet:    Packet{Id: PacketIdServerGameReadyCheck},
+		Timestamp: endTime,
+	}
+}
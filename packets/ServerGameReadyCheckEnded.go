@@ -0,0 +1,15 @@
+package packets
+
+type ServerGameReadyCheckEnded struct {
+	Packet
+	FailedPlayers []int `json:"f"`
+	Cancelled     bool  `json:"c"`
+}
+
+func NewServerGameReadyCheckEnded(failedPlayers []int, cancelled bool) *ServerGameReadyCheckEnded {
+	return &ServerGameReadyCheckEnded{
+		Packet:        Packet{Id: PacketIdServerGameReadyCheckEnded},
+		FailedPlayers: failedPlayers,
+		Cancelled:     cancelled,
+	}
+}
@@ -0,0 +1,15 @@
+package packets
+
+import "example.com/Quaver/Z/objects"
+
+type ServerGameRulesetChanged struct {
+	Packet
+	Ruleset objects.MultiplayerGameRuleset `json:"r"`
+}
+
+func NewServerGameRulesetChanged(ruleset objects.MultiplayerGameRuleset) *ServerGameRulesetChanged {
+	return &ServerGameRulesetChanged{
+		Packet:  Packet{Id: PacketIdServerGameRulesetChanged},
+		Ruleset: ruleset,
+	}
+}
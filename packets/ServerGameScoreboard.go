@@ -0,0 +1,21 @@
+package packets
+
+import (
+	"example.com/Quaver/Z/objects"
+)
+
+type ServerGameScoreboard struct {
+	Packet
+	GameId   string                                    `json:"gid"`
+	Finished bool                                      `json:"f"`
+	Scores   []*objects.MultiplayerGameScoreboardEntry `json:"s"`
+}
+
+func NewServerGameScoreboard(gameId string, finished bool, scores []*objects.MultiplayerGameScoreboardEntry) *ServerGameScoreboard {
+	return &ServerGameScoreboard{
+		Packet:   Packet{Id: PacketIdServerGameScoreboard},
+		GameId:   gameId,
+		Finished: finished,
+		Scores:   scores,
+	}
+}
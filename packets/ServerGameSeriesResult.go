@@ -0,0 +1,13 @@
+package packets
+
+type ServerGameSeriesResult struct {
+	Packet
+	WinnerIds []int `json:"w"`
+}
+
+func NewServerGameSeriesResult(winnerIds []int) *ServerGameSeriesResult {
+	return &ServerGameSeriesResult{
+		Packet:    Packet{Id: PacketIdServerGameSeriesResult},
+		WinnerIds: winnerIds,
+	}
+}
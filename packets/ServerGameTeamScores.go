@@ -0,0 +1,15 @@
+package packets
+
+type ServerGameTeamScores struct {
+	Packet
+	Red  float64 `json:"r"`
+	Blue float64 `json:"b"`
+}
+
+func NewServerGameTeamScores(red float64, blue float64) *ServerGameTeamScores {
+	return &ServerGameTeamScores{
+		Packet: Packet{Id: PacketIdServerGameTeamScores},
+		Red:    red,
+		Blue:   blue,
+	}
+}
@@ -0,0 +1,15 @@
+package packets
+
+type ServerGameTeamWinCount struct {
+	Packet
+	RedWins  int `json:"r"`
+	BlueWins int `json:"b"`
+}
+
+func NewServerGameTeamWinCount(redWins int, blueWins int) *ServerGameTeamWinCount {
+	return &ServerGameTeamWinCount{
+		Packet:   Packet{Id: PacketIdServerGameTeamWinCount},
+		RedWins:  redWins,
+		BlueWins: blueWins,
+	}
+}
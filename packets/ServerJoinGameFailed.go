@@ -6,6 +6,8 @@ const (
 	JoinGameErrorPassword JoinGameError = iota
 	JoinGameErrorFull
 	JoinGameErrorMatchNoExists
+	JoinGameErrorBanned
+	JoinGameErrorTooManySpectators
 )
 
 type ServerJoinGameFailed struct {
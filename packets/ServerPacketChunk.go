@@ -0,0 +1,19 @@
+package packets
+
+type ServerPacketChunk struct {
+	Packet
+	MessageId string `json:"mid"`
+	Index     int    `json:"i"`
+	Count     int    `json:"n"`
+	Data      []byte `json:"d"`
+}
+
+func NewServerPacketChunk(messageId string, index int, count int, data []byte) *ServerPacketChunk {
+	return &ServerPacketChunk{
+		Packet:    Packet{Id: PacketIdServerPacketChunk},
+		MessageId: messageId,
+		Index:     index,
+		Count:     count,
+		Data:      data,
+	}
+}
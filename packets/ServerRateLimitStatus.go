@@ -0,0 +1,17 @@
+package packets
+
+type ServerRateLimitStatus struct {
+	Packet
+	Remaining  float64 `json:"r"`
+	Capacity   float64 `json:"c"`
+	RefillRate float64 `json:"rr"`
+}
+
+func NewServerRateLimitStatus(remaining float64, capacity float64, refillRate float64) *ServerRateLimitStatus {
+	return &ServerRateLimitStatus{
+		Packet:     Packet{Id: PacketIdServerRateLimitStatus},
+		Remaining:  remaining,
+		Capacity:   capacity,
+		RefillRate: refillRate,
+	}
+}
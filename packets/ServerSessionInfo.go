@@ -0,0 +1,21 @@
+package packets
+
+import (
+	"example.com/Quaver/Z/objects"
+)
+
+type ServerSessionInfo struct {
+	Packet
+	User              *objects.PacketUser `json:"u"`
+	MuteTimeRemaining int64               `json:"mr"`
+	GameId            string              `json:"gid"`
+}
+
+func NewServerSessionInfo(user *objects.PacketUser, muteTimeRemaining int64, gameId string) *ServerSessionInfo {
+	return &ServerSessionInfo{
+		Packet:            Packet{Id: PacketIdServerSessionInfo},
+		User:              user,
+		MuteTimeRemaining: muteTimeRemaining,
+		GameId:            gameId,
+	}
+}
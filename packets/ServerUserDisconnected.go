@@ -2,12 +2,14 @@ package packets
 
 type ServerUserDisconnected struct {
 	Packet
-	UserId int `json:"u"`
+	UserId   int   `json:"u"`
+	LastSeen int64 `json:"ls,omitempty"` // Unix milliseconds of when the user went offline
 }
 
-func NewServerUserDisconnected(userId int) *ServerUserDisconnected {
+func NewServerUserDisconnected(userId int, lastSeen int64) *ServerUserDisconnected {
 	return &ServerUserDisconnected{
-		Packet: Packet{Id: PacketIdServerUserDisconnected},
-		UserId: userId,
+		Packet:   Packet{Id: PacketIdServerUserDisconnected},
+		UserId:   userId,
+		LastSeen: lastSeen,
 	}
 }
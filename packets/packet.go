@@ -3,3 +3,8 @@ package packets
 type Packet struct {
 	Id PacketId `json:"id"`
 }
+
+// BinaryEncodable Implemented by packets that can also be sent as a binary frame, for clients that support it
+type BinaryEncodable interface {
+	MarshalBinaryPacket() ([]byte, error)
+}
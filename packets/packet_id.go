@@ -74,28 +74,28 @@ const (
 	PacketIdServerGameNameChanged
 	PacketIdServerGameInvite
 	PacketIdClientGameAcceptInvite
-	PacketIdServerGameHealthTypeChanged // UNUSED
-	PacketIdServerGameLivesChanged      // UNUSED
+	PacketIdServerGameHealthTypeChanged
+	PacketIdServerGameLivesChanged
 	PacketIdServerGameHostRotationChanged
-	PacketIdServerGamePlayerTeamChanged // UNUSED
-	PacketIdClientGamePlayerTeamChanged // UNUSED
-	PacketIdServerGameRulesetChanged    // UNUSED
+	PacketIdServerGamePlayerTeamChanged
+	PacketIdClientGamePlayerTeamChanged
+	PacketIdServerGameRulesetChanged
 	PacketIdServerGameLongNotePercentageChanged
 	PacketIdServerGameMaxPlayersChanged
 	PacketIdServerGameMinimumRateChanged // UNUSED
-	PacketIdServerGameTeamWinCount       // UNUSED
+	PacketIdServerGameTeamWinCount
 	PacketIdServerGamePlayerWinCount
 	PacketIdClientRequestUserStats
 	PacketIdServerUserStats
-	PacketIdServerGamePlayerBattleRoyaleEliminated // UNUSED
+	PacketIdServerGamePlayerBattleRoyaleEliminated
 	PacketIdClientGameKickPlayer
 	PacketIdClientGameTransferHost
-	PacketIdClientGameChangeOtherPlayerTeam // UNUSED
-	PacketIdClientGameChangeRuleset         // UNUSED
+	PacketIdClientGameChangeOtherPlayerTeam
+	PacketIdClientGameChangeRuleset
 	PacketIdClientGameChangeMaxPlayers
 	PacketIdClientGameChangeAutoHostRotation
-	PacketIdClientGameChangeHealthType // UNUSED
-	PacketIdClientGameChangeLivesCount // UNUSED
+	PacketIdClientGameChangeHealthType
+	PacketIdClientGameChangeLivesCount
 	PacketIdClientGameChangeFreeModType
 	PacketIdClientGameHostSelectingMap
 	PacketIdServerGameHostSelectingMap
@@ -139,4 +139,22 @@ const (
 	PacketIdClientGameAutoHost
 	PacketIdServerGameAutoHost
 	PacketIdClientLogout
+	PacketIdClientRequestMatchSettings
+	PacketIdClientRequestSessionInfo
+	PacketIdServerSessionInfo
+	PacketIdServerGameTeamScores
+	PacketIdServerGameScoreboard
+	PacketIdServerGameSeriesResult
+	PacketIdClientPerformanceReport
+	PacketIdClientRequestRateLimitStatus
+	PacketIdServerRateLimitStatus
+	PacketIdClientGameDesyncReport
+	PacketIdServerGameMatchState
+	PacketIdServerPacketChunk
+	PacketIdServerGameReadyCheck
+	PacketIdServerGameReadyCheckEnded
+	PacketIdServerGameMapVote
+	PacketIdClientGameMapDownloadProgress
+	PacketIdServerGameMapDownloadProgress
+	PacketIdServerGamePauseChanged
 )
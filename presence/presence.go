@@ -0,0 +1,152 @@
+package presence
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/sessions"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Returns the redis key for a presence breakdown
+func getPresenceRedisKey(name string) string {
+	return db.RedisKey("presence", name)
+}
+
+// Summary All presence breakdowns computed in a single pass over the registries
+type Summary struct {
+	Total              int
+	ByMode             map[common.Mode]int
+	ByCountry          map[string]int
+	PopularMaps        map[int]int
+	MultiplayerGames   int
+	MultiplayerMatches int
+	MultiplayerPlayers int
+}
+
+// StartAggregator Starts the goroutine that periodically writes the presence breakdowns to redis
+func StartAggregator() {
+	interval := getFlushInterval()
+
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			err := Flush(Aggregate())
+
+			if err != nil {
+				log.Printf("Failed to flush presence counts - %v\n", err)
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Aggregate Computes every presence breakdown from the currently online users and games
+func Aggregate() *Summary {
+	summary := &Summary{
+		ByMode:      map[common.Mode]int{},
+		ByCountry:   map[string]int{},
+		PopularMaps: map[int]int{},
+	}
+
+	for _, user := range sessions.GetVisibleOnlineUsers() {
+		if common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) {
+			continue
+		}
+
+		status := user.GetClientStatus()
+
+		summary.Total++
+		summary.ByMode[status.GameMode]++
+		summary.ByCountry[user.Info.Country]++
+
+		switch status.Status {
+		case objects.ClientStatusPLaying, objects.ClientStatusPaused, objects.ClientStatusMultiplayer:
+			if status.MapId > 0 {
+				summary.PopularMaps[status.MapId]++
+			}
+		}
+	}
+
+	for _, game := range multiplayer.GetGames() {
+		game.RunLocked(func() {
+			summary.MultiplayerGames++
+			summary.MultiplayerPlayers += len(game.Data.PlayerIds)
+
+			if game.Data.InProgress {
+				summary.MultiplayerMatches++
+			}
+		})
+	}
+
+	return summary
+}
+
+// Flush Writes a presence summary to redis in a single transaction, so readers never see a partial update
+func Flush(summary *Summary) error {
+	if !db.IsRedisAvailable() {
+		return nil
+	}
+
+	modes := map[string]interface{}{}
+
+	for mode := common.ModeKeys4; mode < common.ModeEnumMaxValue; mode++ {
+		modes[strconv.Itoa(int(mode))] = summary.ByMode[mode]
+	}
+
+	countries := map[string]interface{}{}
+
+	for country, count := range summary.ByCountry {
+		countries[country] = count
+	}
+
+	maps := map[string]interface{}{}
+
+	for mapId, count := range summary.PopularMaps {
+		maps[strconv.Itoa(mapId)] = count
+	}
+
+	redisKeyTotal := getPresenceRedisKey("total")
+	redisKeyByMode := getPresenceRedisKey("modes")
+	redisKeyByCountry := getPresenceRedisKey("countries")
+	redisKeyPopularMaps := getPresenceRedisKey("maps")
+	redisKeyMultiplayer := getPresenceRedisKey("multiplayer")
+
+	pipeline := db.Redis.TxPipeline()
+	pipeline.Set(db.RedisCtx, redisKeyTotal, summary.Total, 0)
+	pipeline.Del(db.RedisCtx, redisKeyByMode, redisKeyByCountry, redisKeyPopularMaps)
+	pipeline.HSet(db.RedisCtx, redisKeyByMode, modes)
+
+	if len(countries) > 0 {
+		pipeline.HSet(db.RedisCtx, redisKeyByCountry, countries)
+	}
+
+	if len(maps) > 0 {
+		pipeline.HSet(db.RedisCtx, redisKeyPopularMaps, maps)
+	}
+
+	pipeline.HSet(db.RedisCtx, redisKeyMultiplayer,
+		"g", summary.MultiplayerGames,
+		"m", summary.MultiplayerMatches,
+		"p", summary.MultiplayerPlayers)
+
+	_, err := pipeline.Exec(db.RedisCtx)
+	return err
+}
+
+// Returns how often the presence counts are flushed to redis
+func getFlushInterval() time.Duration {
+	if config.Instance == nil {
+		return 0
+	}
+
+	return time.Duration(config.Instance.PresenceFlushInterval) * time.Second
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/handlers"
 	"example.com/Quaver/Z/multiplayer"
+	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/sessions"
 	"example.com/Quaver/Z/utils"
 	"fmt"
+	"github.com/gobwas/httphead"
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
 	"log"
@@ -16,6 +20,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,6 +30,12 @@ type Server struct {
 
 	// If the server is currently started
 	IsStarted bool
+
+	// The amount of open connections for each ip address
+	connectionsPerIp map[string]int
+
+	// Mutex for connectionsPerIp
+	connectionsMutex *sync.Mutex
 }
 
 // NewServer Creates and returns a new server object.
@@ -34,7 +45,9 @@ func NewServer(port int) *Server {
 	}
 
 	s := Server{
-		Port: port,
+		Port:             port,
+		connectionsPerIp: map[string]int{},
+		connectionsMutex: &sync.Mutex{},
 	}
 
 	return &s
@@ -46,23 +59,57 @@ func (s *Server) Start() {
 		log.Fatalln("Server is already started. Cannot start again!")
 	}
 
+	if config.Instance != nil {
+		sessions.CompressionThreshold = config.Instance.Server.CompressionThreshold
+
+		utils.TrustedProxies = config.Instance.Server.TrustedProxies
+
+		if config.Instance.Server.MaxInflatedMessageSize > 0 {
+			utils.MaxInflatedMessageSize = config.Instance.Server.MaxInflatedMessageSize
+		}
+	}
+
 	clearPreviousSessions()
 	startBackgroundWorker()
+	sessions.StartHeartbeat(context.Background(), sessions.GetPingInterval())
+	sessions.StartPingReaper(context.Background(), time.Second, sessions.GetPingTimeout())
+	sessions.StartIdleMonitor(context.Background(), 30*time.Second, sessions.GetIdleTimeout())
+	multiplayer.StartCachedGameRefresher(context.Background())
 
 	log.Printf("Starting server on port: %v\n", s.Port)
 
 	err := http.ListenAndServe(fmt.Sprintf(":%v", s.Port), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		conn, _, _, err := ws.UpgradeHTTP(r, w)
+		ip := utils.GetRequestIpAddress(r)
+
+		if !s.addConnection(ip) {
+			http.Error(w, "Too many connections from your ip address", http.StatusTooManyRequests)
+			log.Printf("[%v] Rejected connection due to the per-ip connection limit\n", ip)
+			return
+		}
+
+		conn, _, handshake, err := newUpgrader().Upgrade(r, w)
 
 		if err != nil {
+			s.removeConnection(ip)
 			log.Println(err)
 			return
 		}
 
+		compressed := hasCompressionExtension(handshake)
+		sessions.SetConnectionCompression(conn, compressed)
+
+		readState := ws.StateServerSide
+
+		if compressed {
+			readState |= ws.StateExtended
+		}
+
 		if strings.Contains(r.RequestURI, "/?login=") {
 			err := handlers.HandleLogin(conn, r)
 
 			if err != nil {
+				s.removeConnection(ip)
+				sessions.SetConnectionCompression(conn, false)
 				log.Println(err)
 				utils.CloseConnection(conn)
 				return
@@ -71,10 +118,12 @@ func (s *Server) Start() {
 
 		// Handle various connection events
 		go func() {
+			defer s.removeConnection(ip)
+			defer sessions.SetConnectionCompression(conn, false)
 			defer conn.Close()
 
 			for {
-				msg, op, err := utils.ReadData(conn, ws.StateServerSide, ws.OpText|ws.OpClose|ws.OpPong)
+				msg, op, err := utils.ReadData(conn, readState, ws.OpText|ws.OpClose|ws.OpPong)
 
 				if err != nil {
 					var opError *net.OpError
@@ -118,6 +167,73 @@ func (s *Server) Start() {
 	}
 }
 
+// Tracks a new connection from an ip address. Returns false if the ip has reached the connection limit.
+func (s *Server) addConnection(ip string) bool {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+
+	if config.Instance != nil && config.Instance.Server.MaxConnectionsPerIp > 0 &&
+		!utils.Includes(config.Instance.Server.ConnectionLimitAllowlist, ip) &&
+		s.connectionsPerIp[ip] >= config.Instance.Server.MaxConnectionsPerIp {
+		return false
+	}
+
+	s.connectionsPerIp[ip]++
+	return true
+}
+
+// Stops tracking a connection from an ip address
+func (s *Server) removeConnection(ip string) {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+
+	s.connectionsPerIp[ip]--
+
+	if s.connectionsPerIp[ip] <= 0 {
+		delete(s.connectionsPerIp, ip)
+	}
+}
+
+// Returns the upgrader for incoming websocket connections. permessage-deflate is only offered if compression is enabled.
+func newUpgrader() ws.HTTPUpgrader {
+	if sessions.CompressionThreshold <= 0 {
+		return ws.HTTPUpgrader{}
+	}
+
+	accepted := false
+
+	return ws.HTTPUpgrader{
+		Negotiate: func(option httphead.Option) (httphead.Option, error) {
+			if accepted || string(option.Name) != utils.PermessageDeflate {
+				return httphead.Option{}, nil
+			}
+
+			// Messages are always compressed with the full window, so offers that restrict it are declined.
+			if bits, ok := option.Parameters.Get("server_max_window_bits"); ok && string(bits) != "15" {
+				return httphead.Option{}, nil
+			}
+
+			accepted = true
+
+			return httphead.NewOption(utils.PermessageDeflate, map[string]string{
+				"server_no_context_takeover": "",
+				"client_no_context_takeover": "",
+			}), nil
+		},
+	}
+}
+
+// Returns if permessage-deflate was negotiated in a websocket handshake
+func hasCompressionExtension(handshake ws.Handshake) bool {
+	for _, extension := range handshake.Extensions {
+		if string(extension.Name) == utils.PermessageDeflate {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Handles new incoming text messages
 func (s *Server) onTextMessage(conn net.Conn, msg []byte) {
 	handlers.HandleIncomingPackets(conn, string(msg))
@@ -152,26 +268,45 @@ func clearPreviousSessions() {
 		panic(err)
 	}
 
-	err = sessions.ClearRedisUserClientStatuses()
+	err = sessions.ClearRedisUserSessions()
 
 	if err != nil {
 		panic(err)
 	}
 
-	err = multiplayer.ClearRedisGames()
+	err = sessions.ClearRedisUserClientStatuses()
 
 	if err != nil {
 		panic(err)
 	}
 
+	if config.Instance != nil && config.Instance.Multiplayer.PreserveRedisGames {
+		deleted, err := multiplayer.ClearOrphanedRedisPlayers()
+
+		if err != nil {
+			panic(err)
+		}
+
+		log.Printf("Cleared %v orphaned multiplayer players from redis\n", deleted)
+	} else {
+		err = multiplayer.ClearRedisGames()
+
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	log.Println("Cleared previous redis sessions")
 }
 
+// The amount of consecutive failed writes before a user is disconnected
+const maxConsecutiveSendFailures = 5
+
 // Handles all operations that happen in the background at intervals to keep the server clean.
 func startBackgroundWorker() {
 	go func() {
 		for {
-			users := sessions.GetOnlineUsers()
+			users := sessions.GetAllSessions()
 
 			for _, user := range users {
 				// Disregard bot users
@@ -185,18 +320,30 @@ func startBackgroundWorker() {
 					user.SetSpammedChatLastTimeCleared(time.Now().UnixMilli())
 				}
 
-				// Ping the user periodically
-				if time.Now().UnixMilli()-user.GetLastPingTimestamp() >= 40_000 {
-					_ = sessions.SendPingToUser(user)
-					sessions.SendPacketToUser(packets.NewServerPing(), user)
-					user.SetLastPingTimestamp()
+				// Let the user know once their mute has run out
+				if user.CheckMuteExpired() && isMuteExpiryNotificationEnabled() {
+					sessions.SendPacketToUser(packets.NewServerNotificationInfo("Your mute has expired. You are now able to chat again."), user)
+					_ = sessions.SendPacketToAllUsers(packets.NewServerUserInfo([]*objects.PacketUser{user.SerializeForPacket()}))
+				}
+
+				// Keep the user's last seen time fresh during long sessions
+				if time.Now().UnixMilli()-user.GetLastActivityTimestamp() >= 300_000 {
+					go user.UpdateLatestActivity()
 				}
 
-				// User hasn't responded to pings in a while, so disconnect them
-				if time.Now().UnixMilli()-user.GetLastPongTimestamp() >= 120_000 ||
-					time.Now().UnixMilli()-user.GetLastWsPongTimestamp() >= 120_000 {
+				// Refresh stats for long sessions, so they reflect scores set elsewhere
+				if interval := getStatsRefreshInterval(); interval > 0 && time.Now().UnixMilli()-user.GetStatsRefreshTimestamp() >= interval.Milliseconds() {
+					go func(user *sessions.User) {
+						if err := user.RefreshStats(interval); err != nil {
+							log.Printf("[%v - %v] Failed to refresh stats - %v\n", user.Info.Username, user.Info.Id, err)
+						}
+					}(user)
+				}
+
+				// Writes to the user keep failing, so the connection is most likely dead
+				if user.GetSendFailureCount() >= maxConsecutiveSendFailures {
 					utils.CloseConnection(user.Conn)
-					log.Printf("[%v - %v] Disconnected due to being unresponsive to pings (timeout)\n", user.Info.Username, user.Info.Id)
+					log.Printf("[%v - %v] Disconnected due to repeated packet send failures\n", user.Info.Username, user.Info.Id)
 				}
 			}
 
@@ -204,3 +351,17 @@ func startBackgroundWorker() {
 		}
 	}()
 }
+
+// Returns if users are notified when their mute expires
+func isMuteExpiryNotificationEnabled() bool {
+	return config.Instance == nil || !config.Instance.DisableMuteExpiryNotifications
+}
+
+// Returns how often online users have their stats refreshed. Zero if disabled.
+func getStatsRefreshInterval() time.Duration {
+	if config.Instance == nil || config.Instance.StatsRefreshInterval <= 0 {
+		return 0
+	}
+
+	return time.Duration(config.Instance.StatsRefreshInterval) * time.Second
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/utils"
+	"net/http"
+	"testing"
+)
+
+func TestConnectionLimitPerIp(t *testing.T) {
+	previous := config.Instance
+	config.Instance = &config.Configuration{}
+	config.Instance.Server.MaxConnectionsPerIp = 2
+	config.Instance.Server.ConnectionLimitAllowlist = []string{"10.0.0.1"}
+	defer func() { config.Instance = previous }()
+
+	s := NewServer(3000)
+
+	if !s.addConnection("1.1.1.1") || !s.addConnection("1.1.1.1") {
+		t.Fatal("expected connections under the limit to be allowed")
+	}
+
+	if s.addConnection("1.1.1.1") {
+		t.Fatal("expected a connection over the limit to be rejected")
+	}
+
+	if !s.addConnection("2.2.2.2") {
+		t.Fatal("expected the limit to be tracked separately for each ip")
+	}
+
+	s.removeConnection("1.1.1.1")
+
+	if !s.addConnection("1.1.1.1") {
+		t.Fatal("expected a connection to be allowed again once another one closed")
+	}
+
+	for i := 0; i < 5; i++ {
+		if !s.addConnection("10.0.0.1") {
+			t.Fatal("expected allowlisted ips not to be limited")
+		}
+	}
+
+	s.removeConnection("2.2.2.2")
+
+	if _, ok := s.connectionsPerIp["2.2.2.2"]; ok {
+		t.Fatal("expected an ip without connections to stop being tracked")
+	}
+}
+
+func TestGetRequestIpAddress(t *testing.T) {
+	previous := utils.TrustedProxies
+	utils.TrustedProxies = []string{"127.0.0.1", "172.16.0.0/12"}
+	defer func() { utils.TrustedProxies = previous }()
+
+	request := func(remoteAddr string, forwardedFor string) *http.Request {
+		r := &http.Request{RemoteAddr: remoteAddr, Header: http.Header{}}
+
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected string
+	}{
+		{"direct", request("1.1.1.1:5000", ""), "1.1.1.1"},
+		{"spoofed header from an untrusted client", request("1.1.1.1:5000", "10.0.0.1"), "1.1.1.1"},
+		{"trusted proxy", request("127.0.0.1:5000", "2.2.2.2"), "2.2.2.2"},
+		{"client prepends a fake hop", request("127.0.0.1:5000", "10.0.0.1, 2.2.2.2"), "2.2.2.2"},
+		{"chain of trusted proxies", request("127.0.0.1:5000", "2.2.2.2, 172.16.0.5"), "2.2.2.2"},
+		{"trusted proxy without the header", request("127.0.0.1:5000", ""), "127.0.0.1"},
+	}
+
+	for _, test := range tests {
+		if ip := utils.GetRequestIpAddress(test.request); ip != test.expected {
+			t.Fatalf("%v: expected %v, got %v", test.name, test.expected, ip)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws"
+)
+
+// CapabilityBinaryPackets Clients with this capability receive packets that have a binary encoding as binary frames
+const CapabilityBinaryPackets = "binary_packets"
+
+// A packet that has been serialized for sending. Packets that implement packets.BinaryEncodable are serialized
+// both ways, so a broadcast can give each user the encoding they support.
+type encodedPacket struct {
+	text   []byte
+	binary []byte
+}
+
+// Serializes a packet to JSON, and to binary if the packet supports it
+func encodePacket(data interface{}) (*encodedPacket, error) {
+	text, err := json.Marshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	packet := &encodedPacket{text: text}
+
+	if encodable, ok := data.(packets.BinaryEncodable); ok {
+		if packet.binary, err = encodable.MarshalBinaryPacket(); err != nil {
+			return nil, err
+		}
+	}
+
+	return packet, nil
+}
+
+// Returns the payload and frame type to send to a user. The user is nil if the connection has no session yet.
+func (p *encodedPacket) forUser(user *User) ([]byte, ws.OpCode) {
+	if p.binary != nil && (p.text == nil || (user != nil && user.HasCapability(CapabilityBinaryPackets))) {
+		return p.binary, ws.OpBinary
+	}
+
+	return p.text, ws.OpText
+}
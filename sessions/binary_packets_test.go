@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"github.com/gobwas/ws"
+	"testing"
+)
+
+func TestEncodedPacketForUser(t *testing.T) {
+	packet, err := encodePacket(packets.NewServerGameJudgements(1, []common.Judgements{common.JudgementMarv}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := NewUser(nil, &db.User{Id: 1, SteamId: "1", Username: "User #1"})
+
+	if _, op := packet.forUser(user); op != ws.OpText {
+		t.Fatal("Expected a text frame for a user without the binary packets capability")
+	}
+
+	user.SetCapabilities([]string{CapabilityBinaryPackets})
+
+	payload, op := packet.forUser(user)
+
+	if op != ws.OpBinary {
+		t.Fatal("Expected a binary frame for a user with the binary packets capability")
+	}
+
+	if len(payload) != 9 || payload[8] != byte(common.JudgementMarv) {
+		t.Fatalf("Unexpected binary payload: %v", payload)
+	}
+
+	text, err := encodePacket(packets.NewServerPing())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, op := text.forUser(user); op != ws.OpText {
+		t.Fatal("Expected a text frame for a packet without a binary encoding")
+	}
+}
@@ -0,0 +1,61 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/utils"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"net"
+	"sync"
+)
+
+// CompressionThreshold Packets of at least this many bytes are compressed for connections that negotiated
+// permessage-deflate. Compression is disabled if zero.
+var CompressionThreshold = 0
+
+var (
+	// Connections that negotiated permessage-deflate during the websocket upgrade
+	compressedConns = map[net.Conn]bool{}
+
+	// Mutex for compressedConns
+	compressedConnsMutex = &sync.RWMutex{}
+)
+
+// SetConnectionCompression Sets if a connection negotiated permessage-deflate, so large packets sent to it
+// can be compressed. Must be called with false once the connection is closed.
+func SetConnectionCompression(conn net.Conn, enabled bool) {
+	compressedConnsMutex.Lock()
+	defer compressedConnsMutex.Unlock()
+
+	if enabled {
+		compressedConns[conn] = true
+	} else {
+		delete(compressedConns, conn)
+	}
+}
+
+// Returns if a connection negotiated permessage-deflate
+func isConnectionCompressed(conn net.Conn) bool {
+	compressedConnsMutex.RLock()
+	defer compressedConnsMutex.RUnlock()
+
+	return compressedConns[conn]
+}
+
+// Writes a text or binary frame to a connection. The frame is compressed if it is over the threshold and the
+// connection supports it. Anything that fails to compress is sent as it is.
+func writeDataFrame(conn net.Conn, op ws.OpCode, payload []byte) error {
+	if CompressionThreshold <= 0 || len(payload) < CompressionThreshold || !isConnectionCompressed(conn) {
+		return wsutil.WriteServerMessage(conn, op, payload)
+	}
+
+	compressed, err := utils.DeflateMessage(payload)
+
+	if err != nil {
+		return wsutil.WriteServerMessage(conn, op, payload)
+	}
+
+	frame := ws.NewFrame(op, true, compressed)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+
+	return ws.WriteFrame(conn, frame)
+}
@@ -0,0 +1,139 @@
+package sessions
+
+import (
+	"bytes"
+	"example.com/Quaver/Z/utils"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"net"
+	"testing"
+)
+
+func TestWriteTextFrameCompression(t *testing.T) {
+	previous := CompressionThreshold
+	CompressionThreshold = 64
+	defer func() { CompressionThreshold = previous }()
+
+	conn, client := net.Pipe()
+	defer conn.Close()
+	defer client.Close()
+
+	payload := bytes.Repeat([]byte(`{"id":1}`), 64)
+
+	for _, compressed := range []bool{false, true} {
+		SetConnectionCompression(conn, compressed)
+
+		go func() { _ = writeDataFrame(conn, ws.OpText, payload) }()
+
+		frame, err := ws.ReadFrame(client)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if frame.Header.Rsv1() != compressed {
+			t.Fatalf("Expected compressed to be %v", compressed)
+		}
+
+		data := frame.Payload
+
+		if compressed {
+			if data, err = utils.InflateMessage(data, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if !bytes.Equal(data, payload) {
+			t.Fatal("Expected the received payload to match the one sent")
+		}
+	}
+
+	SetConnectionCompression(conn, false)
+}
+
+func TestReadCompressedClientFrame(t *testing.T) {
+	conn, client := net.Pipe()
+	defer conn.Close()
+	defer client.Close()
+
+	payload := []byte(`{"id":1,"m":"héllo wörld"}`)
+
+	for _, compressed := range []bool{false, true} {
+		// Masking happens in place, so the payload is copied to keep it intact
+		data := append([]byte{}, payload...)
+
+		if compressed {
+			var err error
+
+			if data, err = utils.DeflateMessage(payload); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Client frames are always masked
+		frame := ws.NewTextFrame(data)
+		frame.Header.Rsv = ws.Rsv(compressed, false, false)
+		frame = ws.MaskFrameInPlace(frame)
+
+		go func() { _ = ws.WriteFrame(client, frame) }()
+
+		msg, op, err := utils.ReadData(conn, ws.StateServerSide|ws.StateExtended, ws.OpText)
+
+		if err != nil {
+			t.Fatalf("Expected the frame to be read (compressed: %v), got %v", compressed, err)
+		}
+
+		if op != ws.OpText || !bytes.Equal(msg, payload) {
+			t.Fatalf("Expected the read payload to match the one sent (compressed: %v)", compressed)
+		}
+	}
+}
+
+func TestReadInvalidUTF8ClientFrame(t *testing.T) {
+	conn, client := net.Pipe()
+	defer conn.Close()
+	defer client.Close()
+
+	data, err := utils.DeflateMessage([]byte{0xff, 0xfe})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frame := ws.NewTextFrame(data)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+	frame = ws.MaskFrameInPlace(frame)
+
+	go func() { _ = ws.WriteFrame(client, frame) }()
+
+	if _, _, err := utils.ReadData(conn, ws.StateServerSide|ws.StateExtended, ws.OpText); err != wsutil.ErrInvalidUTF8 {
+		t.Fatalf("Expected invalid UTF-8 after inflating to be rejected, got %v", err)
+	}
+}
+
+func TestReadOversizedCompressedClientFrame(t *testing.T) {
+	conn, client := net.Pipe()
+	defer conn.Close()
+	defer client.Close()
+
+	previous := utils.MaxInflatedMessageSize
+	utils.MaxInflatedMessageSize = 1024
+	defer func() { utils.MaxInflatedMessageSize = previous }()
+
+	// Repeated bytes compress down to a tiny frame, but inflate to far more than the limit
+	data, err := utils.DeflateMessage(bytes.Repeat([]byte("a"), 1024*1024))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frame := ws.NewTextFrame(data)
+	frame.Header.Rsv = ws.Rsv(true, false, false)
+	frame = ws.MaskFrameInPlace(frame)
+
+	go func() { _ = ws.WriteFrame(client, frame) }()
+
+	if _, _, err := utils.ReadData(conn, ws.StateServerSide|ws.StateExtended, ws.OpText); err != utils.ErrMessageTooLarge {
+		t.Fatalf("Expected a frame that inflates past the limit to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/utils"
+	"log"
+	"time"
+)
+
+// Disconnect Tears down the user's session. The disconnect handlers are run, other users are told the user went
+// offline, the session is removed along with its redis keys, and the connection is closed.
+// Only the first call does anything, so it is safe to call from multiple places.
+func (u *User) Disconnect() {
+	u.disconnectOnce.Do(func() {
+		u.SetDisconnecting()
+
+		userMutex.RLock()
+		handlers := append([]func(user *User){}, disconnectHandlers...)
+		userMutex.RUnlock()
+
+		for _, handler := range handlers {
+			handler(u)
+		}
+
+		// Invisible users already appear offline to everyone else, and users with
+		// sessions on other devices are still online.
+		if !u.IsInvisible() && len(GetUserSessions(u.Info.Id)) <= 1 {
+			lastSeen := time.Now()
+
+			if db.IsRedisAvailable() {
+				if err := setRedisLastSeen(u.Info.Id, lastSeen); err != nil {
+					log.Printf("[%v %v] Failed to store last seen time - %v\n", u.Info.Username, u.Info.Id, err)
+				}
+			}
+
+			err := BroadcastToAllExcept(packets.NewServerUserDisconnected(u.Info.Id, lastSeen.UnixMilli()), u)
+
+			if err != nil {
+				log.Printf("[%v %v] Failed to broadcast disconnect - %v\n", u.Info.Username, u.Info.Id, err)
+			}
+		}
+
+		// Packets that are still queued, such as the reason the user is being logged out, go out before the connection is closed
+		<-u.stopOutboundWriter()
+
+		if err := RemoveUser(u); err != nil {
+			log.Printf("[%v %v] Error while logging out user - %v\n", u.Info.Username, u.Info.Id, err)
+		}
+
+		log.Printf("[%v #%v] Logged out (%v users online).\n", u.Info.Username, u.Info.Id, GetOnlineUserCount())
+
+		if u.Conn != nil {
+			// The peer may have stopped reading, so the close frame can't be allowed to block.
+			_ = u.Conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+			utils.CloseConnection(u.Conn)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package sessions
+
+import (
+	"context"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"log"
+	"time"
+)
+
+// StartIdleMonitor Starts a goroutine that marks users as away once they haven't sent a packet within the idle timeout
+func StartIdleMonitor(ctx context.Context, interval time.Duration, timeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				markIdleUsers(timeout)
+			}
+		}
+	}()
+}
+
+// GetIdleTimeout Returns how long a user can go without sending a packet before they are shown as away
+func GetIdleTimeout() time.Duration {
+	if config.Instance == nil || config.Instance.Server.IdleTimeout <= 0 {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(config.Instance.Server.IdleTimeout) * time.Second
+}
+
+// MarkActive Updates the last time the user sent a packet. If they were shown as away, their status is restored.
+func (u *User) MarkActive() {
+	u.Mutex.Lock()
+	u.lastPacketTimestamp = time.Now().UnixMilli()
+	wasIdle := u.isIdle
+	u.isIdle = false
+	u.Mutex.Unlock()
+
+	if wasIdle {
+		u.broadcastPresence()
+	}
+}
+
+// IsIdle Returns if the user is shown as away due to inactivity
+func (u *User) IsIdle() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.isIdle
+}
+
+// Marks the user as away if they haven't sent a packet within the timeout
+func (u *User) markIdle(timeout time.Duration) {
+	u.Mutex.Lock()
+
+	if u.isIdle || u.isDisconnecting || time.Now().UnixMilli()-u.lastPacketTimestamp < timeout.Milliseconds() {
+		u.Mutex.Unlock()
+		return
+	}
+
+	u.isIdle = true
+	u.Mutex.Unlock()
+
+	u.broadcastPresence()
+}
+
+// Caches the user's current status and sends it to their friends
+func (u *User) broadcastPresence() {
+	if err := addUserClientStatusToRedis(u); err != nil {
+		log.Println(err)
+	}
+
+	_ = BroadcastToFriends(u.Info.Id, packets.NewServerUserStatusSingle(u.Info.Id, u.GetClientStatus()))
+}
+
+// Marks every online user who hasn't sent a packet within the timeout as away
+func markIdleUsers(timeout time.Duration) {
+	for _, user := range GetAllSessions() {
+		if user.Conn == nil || common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) {
+			continue
+		}
+
+		// Only the primary session's status is shown to others
+		if !IsPrimarySession(user) {
+			continue
+		}
+
+		user.markIdle(timeout)
+	}
+}
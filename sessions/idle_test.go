@@ -0,0 +1,27 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"testing"
+)
+
+func TestIdleUserShownAsAway(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1001, SteamId: "1001", Username: "Idle User"})
+	user.status = &objects.ClientStatus{Status: objects.ClientStatusEditing, MapId: 1}
+	user.isIdle = true
+
+	if status := user.GetClientStatus(); status.Status != objects.ClientStatusAway || status.MapId != 1 {
+		t.Fatal("Expected an idle user to be shown as away")
+	}
+
+	if user.status.Status != objects.ClientStatusEditing {
+		t.Fatal("Expected the user's real status to be kept while they are idle")
+	}
+
+	user.isIdle = false
+
+	if user.GetClientStatus().Status != objects.ClientStatusEditing {
+		t.Fatal("Expected the user's status to be restored once they are active again")
+	}
+}
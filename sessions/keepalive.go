@@ -0,0 +1,115 @@
+package sessions
+
+import (
+	"context"
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"log"
+	"time"
+)
+
+// StartPingReaper Starts a goroutine that disconnects users who have stopped answering pings. A user is
+// disconnected once their last pong is older than their last ping by more than the timeout.
+func StartPingReaper(ctx context.Context, interval time.Duration, timeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reapUnresponsiveUsers(timeout)
+			}
+		}
+	}()
+}
+
+// StartHeartbeat Starts a goroutine that pings every online user at an interval, both with a websocket ping
+// and a ping packet. Unanswered pings are picked up by the ping reaper.
+func StartHeartbeat(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pingOnlineUsers()
+			}
+		}
+	}()
+}
+
+// GetPingInterval Returns how often online users are pinged
+func GetPingInterval() time.Duration {
+	if config.Instance == nil || config.Instance.Server.PingInterval <= 0 {
+		return 40 * time.Second
+	}
+
+	return time.Duration(config.Instance.Server.PingInterval) * time.Second
+}
+
+// GetPingTimeout Returns how long a ping can go unanswered before the user is disconnected
+func GetPingTimeout() time.Duration {
+	if config.Instance == nil || config.Instance.Server.PingTimeout <= 0 {
+		return 120 * time.Second
+	}
+
+	return time.Duration(config.Instance.Server.PingTimeout) * time.Second
+}
+
+// Sends a ping to every online user
+func pingOnlineUsers() {
+	for _, user := range GetAllSessions() {
+		if user.Conn == nil || common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) {
+			continue
+		}
+
+		_ = SendPingToUser(user)
+		_ = SendPacketToUser(packets.NewServerPing(), user)
+		user.SetLastPingTimestamp()
+	}
+}
+
+// Disconnects every user that hasn't answered pings within the timeout
+func reapUnresponsiveUsers(timeout time.Duration) {
+	for _, user := range GetAllSessions() {
+		if user.Conn == nil || common.HasUserGroup(user.Info.UserGroups, common.UserGroupBot) {
+			continue
+		}
+
+		// Brand-new sessions may not have responded to their first ping yet
+		if time.Now().UnixMilli()-user.GetConnectedTimestamp() < getPongGracePeriod() {
+			continue
+		}
+
+		lastPing := user.GetLastPingTimestamp()
+
+		if !isPingUnanswered(lastPing, user.GetLastPongTimestamp(), timeout) &&
+			!isPingUnanswered(lastPing, user.GetLastWsPongTimestamp(), timeout) {
+			continue
+		}
+
+		log.Printf("[%v - %v] Disconnected due to being unresponsive to pings (timeout)\n", user.Info.Username, user.Info.Id)
+		go user.Disconnect()
+	}
+}
+
+// Returns if the last pong is older than the last ping by more than the timeout
+func isPingUnanswered(lastPing int64, lastPong int64, timeout time.Duration) bool {
+	return lastPing-lastPong > timeout.Milliseconds()
+}
+
+// Returns the amount of milliseconds a new session is ignored by the ping reaper
+func getPongGracePeriod() int64 {
+	if config.Instance == nil || config.Instance.Server.PongGracePeriod <= 0 {
+		return 30_000
+	}
+
+	return int64(config.Instance.Server.PongGracePeriod) * 1000
+}
@@ -0,0 +1,141 @@
+package sessions
+
+import (
+	"errors"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"github.com/gobwas/ws"
+	"log"
+)
+
+// ErrOutboundQueueFull Returned when a packet couldn't be queued because the connection isn't keeping up
+var ErrOutboundQueueFull = errors.New("outbound packet queue is full")
+
+const (
+	OutboundQueueDrop       = "drop"       // Packets that don't fit in the queue are dropped
+	OutboundQueueDisconnect = "disconnect" // Users whose queue fills up are disconnected
+)
+
+const SendFailureQueueFull = "packet_send_failures_queue_full"
+
+// The frames of a single packet waiting to be written
+type outboundFrames struct {
+	op     ws.OpCode
+	frames [][]byte
+}
+
+// Starts the goroutine that writes the user's queued packets, so only one goroutine ever writes to the connection
+func (u *User) startOutboundWriter() {
+	if u.Conn == nil {
+		return
+	}
+
+	u.outboundMutex.Lock()
+	defer u.outboundMutex.Unlock()
+
+	if u.outbound != nil {
+		return
+	}
+
+	outbound := make(chan outboundFrames, getOutboundQueueSize())
+	done := make(chan struct{})
+	drained := make(chan struct{})
+
+	u.outbound = outbound
+	u.outboundDone = done
+	u.outboundDrained = drained
+
+	go func() {
+		defer close(drained)
+
+		for {
+			select {
+			case queued := <-outbound:
+				_ = writeFrames(u.Conn, u, queued.op, queued.frames)
+			case <-done:
+				u.drainOutbound(outbound)
+				return
+			}
+		}
+	}()
+}
+
+// Writes whatever is left in the queue when the writer is stopped, so final packets such as kick notices still go out
+func (u *User) drainOutbound(outbound chan outboundFrames) {
+	for {
+		select {
+		case queued := <-outbound:
+			_ = writeFrames(u.Conn, u, queued.op, queued.frames)
+		default:
+			return
+		}
+	}
+}
+
+// Stops the user's writer goroutine. Packets sent afterwards are written directly.
+// The returned channel is closed once the packets that were still queued have been written.
+func (u *User) stopOutboundWriter() <-chan struct{} {
+	u.outboundMutex.Lock()
+	defer u.outboundMutex.Unlock()
+
+	if u.outbound == nil {
+		drained := make(chan struct{})
+		close(drained)
+		return drained
+	}
+
+	drained := u.outboundDrained
+
+	close(u.outboundDone)
+	u.outbound = nil
+	u.outboundDone = nil
+	u.outboundDrained = nil
+	return drained
+}
+
+// Queues frames to be written by the user's writer goroutine. Returns false if the user has no writer,
+// in which case the caller should write the frames itself.
+func (u *User) enqueueOutbound(op ws.OpCode, frames [][]byte) (bool, error) {
+	u.outboundMutex.Lock()
+	outbound := u.outbound
+	u.outboundMutex.Unlock()
+
+	if outbound == nil {
+		return false, nil
+	}
+
+	select {
+	case outbound <- outboundFrames{op: op, frames: frames}:
+		return true, nil
+	default:
+	}
+
+	metrics.IncrementCounter(SendFailureQueueFull)
+
+	if getOutboundQueuePolicy() == OutboundQueueDisconnect {
+		log.Printf("[%v - %v] Disconnected due to a full outbound packet queue\n", u.Info.Username, u.Info.Id)
+		_ = u.Conn.Close()
+	} else if Verbose {
+		log.Printf("[%v - %v] Dropped packet due to a full outbound packet queue\n", u.Info.Username, u.Info.Id)
+	}
+
+	return true, ErrOutboundQueueFull
+}
+
+// Returns the amount of packets that can be queued for a connection
+func getOutboundQueueSize() int {
+	if config.Instance == nil || config.Instance.Server.OutboundQueueSize <= 0 {
+		return 256
+	}
+
+	return config.Instance.Server.OutboundQueueSize
+}
+
+// Returns what happens when a connection's outbound queue is full
+func getOutboundQueuePolicy() string {
+	if config.Instance == nil || config.Instance.Server.OutboundQueuePolicy != OutboundQueueDisconnect {
+		return OutboundQueueDrop
+	}
+
+	return OutboundQueueDisconnect
+}
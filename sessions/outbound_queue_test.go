@@ -0,0 +1,158 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"fmt"
+	"github.com/gobwas/ws/wsutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutboundQueueFrameIntegrity(t *testing.T) {
+	const goroutines = 20
+	const packetsPerGoroutine = 50
+
+	conn, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(conn, &db.User{Id: 1, SteamId: "1", Username: "User #1"})
+	user.startOutboundWriter()
+	addUserToMaps(user)
+
+	defer func() {
+		removeUserFromMaps(user)
+		user.stopOutboundWriter()
+	}()
+
+	received := make(chan string)
+	readErrors := make(chan error, 1)
+
+	go func() {
+		for {
+			data, err := wsutil.ReadServerText(client)
+
+			if err != nil {
+				readErrors <- err
+				return
+			}
+
+			var notification packets.ServerNotification
+
+			if err := json.Unmarshal(data, &notification); err != nil {
+				readErrors <- fmt.Errorf("corrupted frame %q: %w", data, err)
+				return
+			}
+
+			received <- notification.Content
+		}
+	}()
+
+	var dropped int
+	droppedMutex := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < packetsPerGoroutine; j++ {
+				err := SendPacketToUser(packets.NewServerNotificationInfo(fmt.Sprintf("%v-%v", i, j)), user)
+
+				if errors.Is(err, ErrOutboundQueueFull) {
+					droppedMutex.Lock()
+					dropped++
+					droppedMutex.Unlock()
+				} else if err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+
+	seen := map[string]bool{}
+	sendersDone := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(sendersDone)
+	}()
+
+	for {
+		select {
+		case content := <-received:
+			if seen[content] {
+				t.Fatalf("Received packet %v twice", content)
+			}
+
+			seen[content] = true
+		case err := <-readErrors:
+			t.Fatal(err)
+		case <-sendersDone:
+			sendersDone = nil
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out with %v packets received and %v dropped", len(seen), dropped)
+		}
+
+		if sendersDone == nil && len(seen)+dropped == goroutines*packetsPerGoroutine {
+			return
+		}
+	}
+}
+
+func TestStopOutboundWriterWaitsForQueuedPackets(t *testing.T) {
+	conn, client := net.Pipe()
+	defer client.Close()
+
+	user := NewUser(conn, &db.User{Id: 1, SteamId: "1", Username: "User #1"})
+	user.startOutboundWriter()
+	addUserToMaps(user)
+	defer removeUserFromMaps(user)
+
+	if err := SendPacketToUser(packets.NewServerNotificationError("You are being logged out"), user); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan string, 1)
+
+	go func() {
+		data, err := wsutil.ReadServerText(client)
+
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+
+		var notification packets.ServerNotification
+		_ = json.Unmarshal(data, &notification)
+		received <- notification.Content
+	}()
+
+	select {
+	case <-user.stopOutboundWriter():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the queue to drain")
+	}
+
+	// The pipe is unbuffered, so the notice has already been read by the time the queue is drained
+	select {
+	case content := <-received:
+		if content != "You are being logged out" {
+			t.Fatalf("Expected the queued notice, got %q", content)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the queued notice to be written before the queue was drained")
+	}
+
+	select {
+	case <-user.stopOutboundWriter():
+	default:
+		t.Fatal("Expected stopping a stopped writer not to block")
+	}
+}
@@ -0,0 +1,97 @@
+package sessions
+
+import (
+	"encoding/json"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/packets"
+	"example.com/Quaver/Z/utils"
+	"fmt"
+	"github.com/gobwas/ws"
+	"log"
+)
+
+// CapabilityPacketChunks Clients with this capability are able to reassemble oversized packets sent in chunks
+const CapabilityPacketChunks = "packet_chunks"
+
+const (
+	OversizedPacketReject = "reject" // Oversized packets are dropped and logged
+	OversizedPacketChunk  = "chunk"  // Oversized packets are split into chunks for clients that support it
+)
+
+const (
+	SendFailureOversized = "packet_send_failures_oversized"
+
+	// The amount of bytes set aside in each chunk for the envelope around the data
+	packetChunkEnvelopeSize = 256
+
+	// The smallest maximum packet size that chunking is done with, so the envelope always fits
+	minChunkedPacketSize = 1024
+)
+
+// Returns the frames to write for a serialized packet. Packets under the size limit are sent as they are, and
+// oversized ones are either rejected or split into chunks for the user depending on the configured policy.
+// Binary packets are never chunked.
+func getOutboundFrames(j []byte, op ws.OpCode, user *User) ([][]byte, error) {
+	maxSize, policy := getOutboundPacketLimit()
+
+	if maxSize <= 0 || len(j) <= maxSize {
+		return [][]byte{j}, nil
+	}
+
+	if policy != OversizedPacketChunk || op != ws.OpText || user == nil || !user.HasCapability(CapabilityPacketChunks) {
+		metrics.IncrementCounter(SendFailureOversized)
+		log.Printf("Rejected outbound packet of %v bytes (limit: %v bytes)\n", len(j), maxSize)
+		return nil, fmt.Errorf("packet of %v bytes exceeds the limit of %v bytes", len(j), maxSize)
+	}
+
+	return chunkPacket(j, maxSize)
+}
+
+// Splits a serialized packet into chunk packets that are each at most maxSize bytes once serialized
+func chunkPacket(j []byte, maxSize int) ([][]byte, error) {
+	if maxSize < minChunkedPacketSize {
+		maxSize = minChunkedPacketSize
+	}
+
+	// Chunk data is base64 encoded, which grows it by a third.
+	chunkSize := (maxSize - packetChunkEnvelopeSize) * 3 / 4
+	count := (len(j) + chunkSize - 1) / chunkSize
+	messageId := utils.GenerateRandomString(16)
+
+	frames := make([][]byte, 0, count)
+
+	for i := 0; i < count; i++ {
+		end := (i + 1) * chunkSize
+
+		if end > len(j) {
+			end = len(j)
+		}
+
+		frame, err := json.Marshal(packets.NewServerPacketChunk(messageId, i, count, j[i*chunkSize:end]))
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal packet chunk: %w", err)
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// Returns the maximum size of an outbound packet in bytes and the policy for packets over it.
+// A size of zero means there's no limit.
+func getOutboundPacketLimit() (int, string) {
+	if config.Instance == nil || config.Instance.Server.MaxPacketSize <= 0 {
+		return 0, OversizedPacketReject
+	}
+
+	policy := config.Instance.Server.OversizedPacketPolicy
+
+	if policy != OversizedPacketChunk {
+		policy = OversizedPacketReject
+	}
+
+	return config.Instance.Server.MaxPacketSize, policy
+}
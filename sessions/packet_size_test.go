@@ -0,0 +1,46 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"example.com/Quaver/Z/packets"
+	"testing"
+)
+
+func TestChunkPacket(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"id":1,"u":"ü"}`), 500)
+
+	frames, err := chunkPacket(data, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(frames) < 2 {
+		t.Fatalf("Expected multiple chunks, got %v", len(frames))
+	}
+
+	var reassembled []byte
+
+	for i, frame := range frames {
+		if len(frame) > 2048 {
+			t.Fatalf("Chunk %v is %v bytes, which is over the limit", i, len(frame))
+		}
+
+		var chunk packets.ServerPacketChunk
+
+		if err := json.Unmarshal(frame, &chunk); err != nil {
+			t.Fatal(err)
+		}
+
+		if chunk.Index != i || chunk.Count != len(frames) {
+			t.Fatalf("Unexpected chunk index %v/%v", chunk.Index, chunk.Count)
+		}
+
+		reassembled = append(reassembled, chunk.Data...)
+	}
+
+	if !bytes.Equal(reassembled, data) {
+		t.Fatal("Expected the chunks to reassemble into the original packet")
+	}
+}
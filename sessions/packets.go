@@ -1,60 +1,302 @@
 package sessions
 
 import (
-	"encoding/json"
+	"errors"
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/metrics"
+	"example.com/Quaver/Z/utils"
+	"fmt"
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
+	"log"
 	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Verbose Logs every failed packet write when enabled, on top of the metrics counters
+var Verbose = false
+
+// WriteTimeout The amount of time a write to a connection can take before it is abandoned and the connection closed
+var WriteTimeout = 10 * time.Second
+
+// ErrWriteTimeout Returned when a write didn't complete within WriteTimeout. Wraps os.ErrDeadlineExceeded.
+var ErrWriteTimeout = fmt.Errorf("packet write timed out: %w", os.ErrDeadlineExceeded)
+
+const (
+	SendFailureTimeout    = "packet_send_failures_timeout"
+	SendFailureBrokenPipe = "packet_send_failures_broken_pipe"
+	SendFailureMarshal    = "packet_send_failures_marshal"
+	SendFailureOther      = "packet_send_failures_other"
 )
 
 func SendPingToUser(user *User) error {
 	user.ConnMutex.Lock()
 	defer user.ConnMutex.Unlock()
 
-	return wsutil.WriteServerMessage(user.Conn, ws.OpPing, []byte("ping"))
+	return writeWithDeadline(user.Conn, func() error {
+		return wsutil.WriteServerMessage(user.Conn, ws.OpPing, []byte("ping"))
+	})
+}
+
+// Runs a write to a connection with WriteTimeout as its deadline. A connection that times out has stopped reading,
+// so it is closed rather than being written to again.
+func writeWithDeadline(conn net.Conn, write func() error) error {
+	if WriteTimeout > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		defer conn.SetWriteDeadline(time.Time{})
+	}
+
+	err := write()
+
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		_ = conn.Close()
+		return fmt.Errorf("%w: %v", ErrWriteTimeout, err)
+	}
+
+	return err
+}
+
+// SendPacketErrors The failed writes of a packet that was sent to multiple users
+type SendPacketErrors []error
+
+func (e SendPacketErrors) Error() string {
+	messages := make([]string, 0, len(e))
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("failed to send packet to %v users: %v", len(e), strings.Join(messages, "; "))
 }
 
 // SendPacketToConnection Sends a packet to a given connection
-func SendPacketToConnection(data interface{}, conn net.Conn) {
+func SendPacketToConnection(data interface{}, conn net.Conn) error {
 	if conn == nil {
-		return
+		return nil
+	}
+
+	packet, err := marshalPacket(data)
+
+	if err != nil {
+		return err
+	}
+
+	return sendBytesToConnection(packet, conn)
+}
+
+// SendBinaryPacketToConnection Sends an already encoded binary packet to a given connection
+func SendBinaryPacketToConnection(data []byte, conn net.Conn) error {
+	return sendBytesToConnection(&encodedPacket{binary: data}, conn)
+}
+
+// Serializes a packet, keeping track of failures
+func marshalPacket(data interface{}) (*encodedPacket, error) {
+	packet, err := encodePacket(data)
+
+	if err != nil {
+		metrics.IncrementCounter(SendFailureMarshal)
+
+		if Verbose {
+			log.Printf("Failed to marshal packet - %v\n", err)
+		}
+
+		return nil, fmt.Errorf("failed to marshal packet: %w", err)
+	}
+
+	return packet, nil
+}
+
+// Writes an already serialized packet to a given connection
+func sendBytesToConnection(packet *encodedPacket, conn net.Conn) error {
+	if conn == nil {
+		return nil
 	}
 
 	user := GetUserByConnection(conn)
+	payload, op := packet.forUser(user)
+
+	frames, err := getOutboundFrames(payload, op, user)
+
+	if err != nil {
+		return err
+	}
+
+	if user != nil {
+		if queued, err := user.enqueueOutbound(op, frames); queued {
+			return err
+		}
+	}
+
+	return writeFrames(conn, user, op, frames)
+}
+
+// Writes frames to a connection one after another. The user is nil if the connection doesn't have a session yet.
+func writeFrames(conn net.Conn, user *User, op ws.OpCode, frames [][]byte) error {
 	if user != nil {
 		user.ConnMutex.Lock()
 		defer user.ConnMutex.Unlock()
 	}
 
-	j, err := json.Marshal(data)
+	err := writeWithDeadline(conn, func() error {
+		for _, frame := range frames {
+			if err := writeDataFrame(conn, op, frame); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 
 	if err != nil {
-		return
+		metrics.IncrementCounter(classifySendError(err))
+
+		if user != nil {
+			user.IncrementSendFailureCount()
+		}
+
+		if Verbose {
+			log.Printf("Failed to write packet to %v - %v\n", conn.RemoteAddr(), err)
+		}
+
+		return err
+	}
+
+	if user != nil {
+		user.ResetSendFailureCount()
 	}
 
-	err = wsutil.WriteServerText(conn, j)
+	return nil
+}
 
-	if err != nil {
-		return
+// Returns the metrics counter that a failed write belongs to
+func classifySendError(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrWriteTimeout), errors.As(err, &netErr) && netErr.Timeout():
+		return SendFailureTimeout
+	case errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.ECONNRESET), errors.Is(err, net.ErrClosed):
+		return SendFailureBrokenPipe
+	default:
+		return SendFailureOther
 	}
 }
 
 // SendPacketToUser Sends a packet to a given user
-func SendPacketToUser(data interface{}, user *User) {
-	SendPacketToConnection(data, user.Conn)
-	return
+func SendPacketToUser(data interface{}, user *User) error {
+	return SendPacketToConnection(data, user.Conn)
 }
 
-// SendPacketToUsers Sends a packet to a list of users
-func SendPacketToUsers(data interface{}, users ...*User) {
+// SendPacketToUsers Sends a packet to a list of users. The packet is serialized once, and rejected before
+// anything is sent if that fails. Failed writes are returned together as SendPacketErrors.
+func SendPacketToUsers(data interface{}, users ...*User) error {
+	packet, err := marshalPacket(data)
+
+	if err != nil {
+		return err
+	}
+
+	var failures SendPacketErrors
+	failuresMutex := &sync.Mutex{}
+
+	send := func(user *User) {
+		if err := sendBytesToConnection(packet, user.Conn); err != nil {
+			failuresMutex.Lock()
+			failures = append(failures, fmt.Errorf("user %v: %w", user.Info.Id, err))
+			failuresMutex.Unlock()
+		}
+	}
+
+	workers := getBroadcastWorkerCount()
+
+	if workers <= 1 || len(users) <= 1 {
+		for _, user := range users {
+			send(user)
+		}
+
+		return failures.orNil()
+	}
+
+	if workers > len(users) {
+		workers = len(users)
+	}
+
+	queue := make(chan *User)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for user := range queue {
+				send(user)
+			}
+		}()
+	}
+
 	for _, user := range users {
-		SendPacketToUser(data, user)
+		queue <- user
+	}
+
+	close(queue)
+	wg.Wait()
+	return failures.orNil()
+}
+
+// Returns nil if there were no failures, so callers can compare against nil as usual
+func (e SendPacketErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
 	}
+
+	return e
 }
 
-// SendPacketToAllUsers Sends a packet to every online user
-func SendPacketToAllUsers(data interface{}) {
-	for _, user := range GetOnlineUsers() {
-		SendPacketToUser(data, user)
+// Returns the amount of goroutines a broadcast is fanned out across
+func getBroadcastWorkerCount() int {
+	if config.Instance == nil || config.Instance.Server.BroadcastWorkers <= 0 {
+		return 8
 	}
+
+	return config.Instance.Server.BroadcastWorkers
+}
+
+// SendPacketToAllUsers Sends a packet to every online user. The online users are snapshotted first,
+// so the session lock isn't held during the writes.
+func SendPacketToAllUsers(data interface{}) error {
+	return SendPacketToUsers(data, GetOnlineUsers()...)
+}
+
+// BroadcastToAllExcept Sends a packet to every online user other than the excluded one. Sends to everyone
+// if exclude is nil.
+func BroadcastToAllExcept(data interface{}, exclude *User) error {
+	if exclude == nil {
+		return SendPacketToAllUsers(data)
+	}
+
+	users := utils.Filter(GetOnlineUsers(), func(x *User) bool { return x.Info.Id != exclude.Info.Id })
+	return SendPacketToUsers(data, users...)
+}
+
+// BroadcastToFriends Sends a packet to every online user who has a user on their friends list, so presence updates
+// only reach the users who care about them. Nothing is sent while the user is invisible, and invisible friends are skipped.
+func BroadcastToFriends(userId int, data interface{}) error {
+	if user := GetUserById(userId); user != nil && user.IsInvisible() {
+		return nil
+	}
+
+	return SendPacketToUsers(data, getFriendsOf(userId)...)
+}
+
+// Returns every visible session whose user has a given user on their friends list
+func getFriendsOf(userId int) []*User {
+	return utils.Filter(GetAllSessions(), func(x *User) bool {
+		return x.Info.Id != userId && !x.IsInvisible() && x.HasFriend(userId)
+	})
 }
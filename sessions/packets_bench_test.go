@@ -0,0 +1,66 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
+	"io"
+	"net"
+	"testing"
+)
+
+// Creates users whose connections discard everything written to them
+func newBenchmarkUsers(b *testing.B, count int) []*User {
+	users := make([]*User, 0, count)
+
+	for i := 0; i < count; i++ {
+		conn, other := net.Pipe()
+		go io.Copy(io.Discard, other)
+
+		b.Cleanup(func() {
+			_ = conn.Close()
+			_ = other.Close()
+		})
+
+		users = append(users, NewUser(conn, &db.User{Id: i + 1, Username: "User"}))
+	}
+
+	return users
+}
+
+// Returns a packet that is reasonably expensive to serialize
+func newBenchmarkPacket() interface{} {
+	users := make([]*objects.PacketUser, 0, 100)
+
+	for i := 0; i < 100; i++ {
+		users = append(users, &objects.PacketUser{Id: i, Username: "User", Country: "US"})
+	}
+
+	return packets.NewServerUserInfo(users)
+}
+
+func BenchmarkSendPacketPerUser(b *testing.B) {
+	users := newBenchmarkUsers(b, 100)
+	packet := newBenchmarkPacket()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, user := range users {
+			_ = SendPacketToUser(packet, user)
+		}
+	}
+}
+
+func BenchmarkSendPacketToUsers(b *testing.B) {
+	users := newBenchmarkUsers(b, 100)
+	packet := newBenchmarkPacket()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = SendPacketToUsers(packet, users...)
+	}
+}
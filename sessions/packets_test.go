@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"errors"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSendPacketToUsersReturnsWriteFailures(t *testing.T) {
+	closed, other := net.Pipe()
+	_ = closed.Close()
+	_ = other.Close()
+
+	user1 := NewUser(closed, &db.User{Id: 1, SteamId: "1", Username: "User #1"})
+	user2 := NewUser(nil, &db.User{Id: 2, SteamId: "2", Username: "User #2"})
+
+	err := SendPacketToUsers(packets.NewServerPing(), user1, user2)
+
+	var failures SendPacketErrors
+
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected SendPacketErrors, got: %v", err)
+	}
+
+	if len(failures) != 1 {
+		t.Fatalf("Expected 1 failed write, got %v", len(failures))
+	}
+
+	if err := SendPacketToUsers(packets.NewServerPing(), user2); err != nil {
+		t.Fatalf("Expected no error for a user without a connection, got: %v", err)
+	}
+}
+
+func TestSendPacketToConnectionTimesOut(t *testing.T) {
+	previous := WriteTimeout
+	WriteTimeout = 50 * time.Millisecond
+	defer func() { WriteTimeout = previous }()
+
+	// Nothing reads from the other end, so the write blocks until the deadline.
+	conn, other := net.Pipe()
+	defer other.Close()
+
+	err := SendPacketToConnection(packets.NewServerPing(), conn)
+
+	if !errors.Is(err, ErrWriteTimeout) || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Expected a write timeout, got: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/packets"
+	"math"
+	"time"
+)
+
+// CapabilityRateLimitStatus Clients with this capability are told when their inbound packet budget runs low
+const CapabilityRateLimitStatus = "rate_limit_status"
+
+// The fraction of the budget that is considered low, at which point capable clients are notified
+const lowPacketBudgetRatio = 0.2
+
+// TakePacketToken Takes a token from the user's inbound packet budget. Returns false if the budget is exhausted,
+// in which case the packet should be dropped. Always succeeds if rate limiting is disabled.
+func (u *User) TakePacketToken() bool {
+	rate, capacity := getPacketRateLimit()
+
+	if rate <= 0 {
+		return true
+	}
+
+	u.Mutex.Lock()
+	u.refillPacketTokens(rate, capacity)
+
+	if u.packetTokens < 1 {
+		u.Mutex.Unlock()
+		return false
+	}
+
+	u.packetTokens--
+
+	notify := false
+
+	if u.packetTokens < capacity*lowPacketBudgetRatio {
+		notify = !u.isPacketBudgetLow
+		u.isPacketBudgetLow = true
+	} else {
+		u.isPacketBudgetLow = false
+	}
+
+	u.Mutex.Unlock()
+
+	if notify && u.HasCapability(CapabilityRateLimitStatus) {
+		u.SendPacketBudget()
+	}
+
+	return true
+}
+
+// SendPacketBudget Sends the user the current state of their inbound packet budget
+func (u *User) SendPacketBudget() {
+	rate, capacity := getPacketRateLimit()
+
+	u.Mutex.Lock()
+	u.refillPacketTokens(rate, capacity)
+	remaining := u.packetTokens
+	u.Mutex.Unlock()
+
+	if rate <= 0 {
+		remaining = capacity
+	}
+
+	SendPacketToUser(packets.NewServerRateLimitStatus(math.Floor(remaining), capacity, rate), u)
+}
+
+// Refills the user's packet tokens for the time since they were last refilled. The user must be locked by the caller.
+func (u *User) refillPacketTokens(rate float64, capacity float64) {
+	now := time.Now().UnixMilli()
+
+	if u.packetTokensRefilled == 0 {
+		u.packetTokens = capacity
+	} else {
+		u.packetTokens = math.Min(capacity, u.packetTokens+float64(now-u.packetTokensRefilled)/1000*rate)
+	}
+
+	u.packetTokensRefilled = now
+}
+
+// Returns the amount of packets a user can send per second, and how many they can send in a burst.
+// Rate limiting is disabled if the rate is zero.
+func getPacketRateLimit() (float64, float64) {
+	if config.Instance == nil || config.Instance.Server.PacketRateLimit <= 0 {
+		return 0, 0
+	}
+
+	rate := float64(config.Instance.Server.PacketRateLimit)
+	burst := float64(config.Instance.Server.PacketRateLimitBurst)
+
+	if burst < rate {
+		burst = rate
+	}
+
+	return rate, burst
+}
@@ -1,25 +1,70 @@
 package sessions
 
 import (
+	"errors"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
+	"fmt"
 	"strconv"
+	"sync"
+	"time"
 )
 
-// UpdateRedisOnlineUserCount Updates the online user count in Redis
+// The last online user count that was published, or -1 if none has been published yet
+var (
+	lastPublishedOnlineUserCount      = -1
+	lastPublishedOnlineUserCountMutex = &sync.Mutex{}
+)
+
+// UpdateRedisOnlineUserCount Updates the online user count in Redis. The key is kept for subscribers that
+// need the current value, and changes are also published.
 func UpdateRedisOnlineUserCount() error {
-	_, err := db.Redis.Set(db.RedisCtx, "quaver:server:online_users", GetOnlineUserCount(), 0).Result()
+	_, err := db.Redis.Set(db.RedisCtx, db.RedisKey("online_users"), GetVisibleOnlineUserCount(), 0).Result()
+
+	if err != nil {
+		return err
+	}
+
+	return PublishOnlineUserCount()
+}
+
+// PublishOnlineUserCount Publishes the online user count to subscribers if it has changed since it was last published
+func PublishOnlineUserCount() error {
+	lastPublishedOnlineUserCountMutex.Lock()
+	defer lastPublishedOnlineUserCountMutex.Unlock()
+
+	count := GetVisibleOnlineUserCount()
+
+	if count == lastPublishedOnlineUserCount {
+		return nil
+	}
+
+	err := db.Redis.Publish(db.RedisCtx, db.RedisChannelOnlineUserUpdates, count).Err()
 
 	if err != nil {
 		return err
 	}
 
+	lastPublishedOnlineUserCount = count
 	return nil
 }
 
 // ClearRedisUserTokens Clears all the user session tokens from Redis.
 // This should only be done once on server start.
 func ClearRedisUserTokens() error {
-	err := db.ClearRedisKeysWithPattern("quaver:server:session:*")
+	err := db.ClearRedisKeysWithPattern(db.RedisKey("session", "*"))
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClearRedisUserSessions Clears the sets of each user's active sessions from Redis.
+// This should only be done once on server start.
+func ClearRedisUserSessions() error {
+	err := db.ClearRedisKeysWithPattern(db.RedisKey("user_sessions", "*"))
 
 	if err != nil {
 		return err
@@ -30,7 +75,7 @@ func ClearRedisUserTokens() error {
 
 // ClearRedisUserClientStatuses Clears all the client statuses from Redis
 func ClearRedisUserClientStatuses() error {
-	err := db.ClearRedisKeysWithPattern("quaver:server:user_status:*")
+	err := db.ClearRedisKeysWithPattern(db.RedisKey("user_status", "*"))
 
 	if err != nil {
 		return err
@@ -39,9 +84,85 @@ func ClearRedisUserClientStatuses() error {
 	return nil
 }
 
+// RefreshAllClientStatuses Rewrites the cached client status of every online user to redis, along with the
+// online user count. Used to recover after redis has been flushed or migrated. Returns the amount of users refreshed.
+func RefreshAllClientStatuses() (int, error) {
+	if !db.IsRedisAvailable() {
+		return 0, errors.New("redis is unavailable")
+	}
+
+	refreshed := 0
+
+	for _, user := range GetVisibleOnlineUsers() {
+		if user.IsDisconnecting() {
+			continue
+		}
+
+		if err := addUserClientStatusToRedis(user); err != nil {
+			return refreshed, err
+		}
+
+		refreshed++
+	}
+
+	if err := UpdateRedisOnlineUserCount(); err != nil {
+		return refreshed, err
+	}
+
+	return refreshed, nil
+}
+
 // Adds a user's session token to redis
 func addUserTokenToRedis(user *User) error {
-	_, err := db.Redis.Set(db.RedisCtx, user.getRedisSessionKey(), strconv.Itoa(user.Info.Id), 0).Result()
+	err := db.WithRetry(func() error {
+		return db.Redis.Set(db.RedisCtx, user.getRedisSessionKey(), strconv.Itoa(user.Info.Id), getSessionTokenTtl()).Err()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RefreshRedisUserToken Extends the expiry of a user's session token, so it only expires once the user stops responding
+func RefreshRedisUserToken(user *User) error {
+	if !db.IsRedisAvailable() {
+		return nil
+	}
+
+	_, err := db.Redis.Expire(db.RedisCtx, user.getRedisSessionKey(), getSessionTokenTtl()).Result()
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Returns how long a session token lives in redis without being refreshed
+func getSessionTokenTtl() time.Duration {
+	if config.Instance == nil || config.Instance.Server.SessionTokenTtl <= 0 {
+		return 24 * time.Hour
+	}
+
+	return time.Duration(config.Instance.Server.SessionTokenTtl) * time.Second
+}
+
+// Adds a session token to the set of a user's active sessions
+func addUserSessionToRedis(user *User) error {
+	_, err := db.Redis.SAdd(db.RedisCtx, user.getRedisUserSessionsKey(), user.token).Result()
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Removes a session token from the set of a user's active sessions
+func removeUserSessionFromRedis(user *User) error {
+	_, err := db.Redis.SRem(db.RedisCtx, user.getRedisUserSessionsKey(), user.token).Result()
 
 	if err != nil {
 		return err
@@ -63,6 +184,21 @@ func removeUserTokenFromRedis(user *User) error {
 
 // Adds a user's client status to redis
 func addUserClientStatusToRedis(user *User) error {
+	// Client statuses are non-essential, so they aren't cached while redis is down.
+	if !db.IsRedisAvailable() {
+		return nil
+	}
+
+	// Invisible users appear offline, so they have no status.
+	if user.IsInvisible() {
+		return nil
+	}
+
+	// Users on multiple devices are shown with the status of their primary session.
+	if !IsPrimarySession(user) {
+		return nil
+	}
+
 	userStatus := user.GetClientStatus()
 
 	status := []string{
@@ -71,7 +207,9 @@ func addUserClientStatusToRedis(user *User) error {
 		"c", userStatus.Content,
 	}
 
-	_, err := db.Redis.HSet(db.RedisCtx, user.getRedisClientStatusKey(), status).Result()
+	err := db.WithRetry(func() error {
+		return db.Redis.HSet(db.RedisCtx, user.getRedisClientStatusKey(), status).Err()
+	})
 
 	if err != nil {
 		return err
@@ -90,3 +228,82 @@ func removeUserClientStatusFromRedis(user *User) error {
 
 	return nil
 }
+
+// GetLastSeen Returns when a user was last online. The zero time is returned if they have never been seen.
+func GetLastSeen(id int) (time.Time, error) {
+	lastSeen, err := GetLastSeenMany([]int{id})
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return lastSeen[id], nil
+}
+
+// GetLastSeenMany Returns when each of the given users was last online, fetched in a single round trip.
+// Users who have never been seen are left out.
+func GetLastSeenMany(ids []int) (map[int]time.Time, error) {
+	lastSeen := map[int]time.Time{}
+
+	if len(ids) == 0 {
+		return lastSeen, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		keys = append(keys, getRedisLastSeenKey(id))
+	}
+
+	values, err := db.Redis.MGet(db.RedisCtx, keys...).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		// Users who have never been seen don't have a key
+		str, ok := value.(string)
+
+		if !ok {
+			continue
+		}
+
+		seen, err := parseLastSeen(str)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lastSeen[ids[i]] = seen
+	}
+
+	return lastSeen, nil
+}
+
+// Stores when a user was last online. The key never expires, so it outlives the user's session.
+func setRedisLastSeen(id int, lastSeen time.Time) error {
+	_, err := db.Redis.Set(db.RedisCtx, getRedisLastSeenKey(id), lastSeen.UnixMilli(), 0).Result()
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Parses a last seen timestamp stored in redis as unix milliseconds
+func parseLastSeen(value string) (time.Time, error) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last seen timestamp %q: %w", value, err)
+	}
+
+	return time.UnixMilli(ms), nil
+}
+
+// Returns the redis key for when a user was last online
+func getRedisLastSeenKey(id int) string {
+	return db.RedisKey("last_seen", strconv.Itoa(id))
+}
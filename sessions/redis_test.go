@@ -0,0 +1,52 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"example.com/Quaver/Z/db"
+	"testing"
+	"time"
+)
+
+func TestLastSeenRoundTrip(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Instance == nil {
+		return
+	}
+
+	db.InitializeRedis()
+
+	const seenId, unseenId = -1000, -1001
+	seen := time.UnixMilli(1700000000123)
+
+	defer db.Redis.Del(db.RedisCtx, getRedisLastSeenKey(seenId), getRedisLastSeenKey(unseenId))
+	db.Redis.Del(db.RedisCtx, getRedisLastSeenKey(unseenId))
+
+	if err := setRedisLastSeen(seenId, seen); err != nil {
+		t.Fatal(err)
+	}
+
+	lastSeen, err := GetLastSeen(seenId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !lastSeen.Equal(seen) {
+		t.Fatalf("expected last seen to be %v, got %v", seen, lastSeen)
+	}
+
+	if lastSeen, err = GetLastSeen(unseenId); err != nil || !lastSeen.IsZero() {
+		t.Fatalf("expected the zero time for a user who was never seen, got %v (%v)", lastSeen, err)
+	}
+
+	many, err := GetLastSeenMany([]int{unseenId, seenId})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := many[unseenId]; ok || len(many) != 1 || !many[seenId].Equal(seen) {
+		t.Fatalf("expected only the seen user to be returned, got %v", many)
+	}
+}
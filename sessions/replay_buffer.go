@@ -0,0 +1,58 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/config"
+	"log"
+	"sync/atomic"
+)
+
+const defaultMaxBufferedReplayFrames = 50_000
+
+var (
+	// The total amount of replay frame packets buffered across every user
+	bufferedReplayFrames int64
+
+	// If buffering is currently degraded because the cap was reached
+	replayBufferDegraded int32
+)
+
+// GetBufferedReplayFrameCount Returns the total amount of replay frame packets buffered across every user
+func GetBufferedReplayFrameCount() int64 {
+	return atomic.LoadInt64(&bufferedReplayFrames)
+}
+
+// Reserves space for a buffered replay frame packet. Returns false if the global cap has been reached,
+// in which case frames are only forwarded live and spectators joining mid-play won't receive the backlog.
+func reserveReplayFrame() bool {
+	if atomic.AddInt64(&bufferedReplayFrames, 1) <= getMaxBufferedReplayFrames() {
+		if atomic.CompareAndSwapInt32(&replayBufferDegraded, 1, 0) {
+			log.Println("Replay frame buffering has recovered below the cap")
+		}
+
+		return true
+	}
+
+	atomic.AddInt64(&bufferedReplayFrames, -1)
+
+	if atomic.CompareAndSwapInt32(&replayBufferDegraded, 0, 1) {
+		log.Printf("WARNING: Reached the cap of %v buffered replay frames. New frames will only be sent live.\n", getMaxBufferedReplayFrames())
+	}
+
+	return false
+}
+
+// Releases the space of buffered replay frame packets
+func releaseReplayFrames(count int) {
+	if count > 0 {
+		atomic.AddInt64(&bufferedReplayFrames, -int64(count))
+	}
+}
+
+// Returns the maximum amount of replay frame packets that can be buffered across every user
+func getMaxBufferedReplayFrames() int64 {
+	if config.Instance == nil || config.Instance.MaxBufferedReplayFrames <= 0 {
+		return defaultMaxBufferedReplayFrames
+	}
+
+	return int64(config.Instance.MaxBufferedReplayFrames)
+}
@@ -2,18 +2,22 @@ package sessions
 
 import (
 	"example.com/Quaver/Z/objects"
+	"example.com/Quaver/Z/packets"
 	"net"
 	"strings"
 	"sync"
 )
 
 var (
-	// mutex used for thread-safe access to users
-	userMutex = &sync.Mutex{}
+	// mutex used for thread-safe access to users. Lookups only take it in read mode.
+	userMutex = &sync.RWMutex{}
 
-	// A map to users with the key being their user id
+	// A map to users with the key being their user id. Holds the primary session of users with multiple sessions.
 	userIdToUser = map[int]*User{}
 
+	// Every session of each user, oldest first. The first session is the primary one.
+	userIdToSessions = map[int][]*User{}
+
 	// A map to users with the key being their username
 	usernameToUser = map[string]*User{}
 
@@ -25,10 +29,14 @@ var (
 
 	// Handlers that run when a spectator stops spectating someone
 	spectatorLeftHandlers = make([]func(user *User, spectator *User), 0)
+
+	// Handlers that run when a user disconnects, before their session is removed
+	disconnectHandlers = make([]func(user *User), 0)
 )
 
 // AddUser Adds a user session
 func AddUser(user *User) error {
+	user.startOutboundWriter()
 	addUserToMaps(user)
 
 	err := UpdateRedisOnlineUserCount()
@@ -44,14 +52,26 @@ func AddUser(user *User) error {
 		return err
 	}
 
+	err = addUserSessionToRedis(user)
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // RemoveUser Removes a user session
 func RemoveUser(user *User) error {
-	removeUserFromMaps(user)
+	user.SetDisconnecting()
+	primary := removeUserFromMaps(user)
+	user.stopOutboundWriter()
 	user.StopSpectatingAll()
 
+	user.Mutex.Lock()
+	user.ClearReplayFrames()
+	user.Mutex.Unlock()
+
 	err := UpdateRedisOnlineUserCount()
 
 	if err != nil {
@@ -64,6 +84,20 @@ func RemoveUser(user *User) error {
 		return err
 	}
 
+	err = removeUserSessionFromRedis(user)
+
+	if err != nil {
+		return err
+	}
+
+	// Last seen is best-effort, so it shouldn't hold up the disconnect.
+	go user.UpdateLatestActivity()
+
+	// The user is still online on another device, so their status is taken over by the new primary session.
+	if primary != nil {
+		return addUserClientStatusToRedis(primary)
+	}
+
 	err = removeUserClientStatusFromRedis(user)
 
 	if err != nil {
@@ -73,46 +107,76 @@ func RemoveUser(user *User) error {
 	return nil
 }
 
+// GetUserSessions Returns every active session of a user, oldest first. The first one is the primary session,
+// which is the one returned by GetUserById and whose client status is used for presence.
+func GetUserSessions(id int) []*User {
+	userMutex.RLock()
+	defer userMutex.RUnlock()
+
+	return append([]*User{}, userIdToSessions[id]...)
+}
+
+// GetAllSessions Returns every active session, including the secondary sessions of users on multiple devices
+func GetAllSessions() []*User {
+	userMutex.RLock()
+	defer userMutex.RUnlock()
+
+	users := make([]*User, 0, len(connToUser))
+
+	for _, sessions := range userIdToSessions {
+		users = append(users, sessions...)
+	}
+
+	return users
+}
+
+// IsPrimarySession Returns if a session is the primary session of its user
+func IsPrimarySession(user *User) bool {
+	return GetUserById(user.Info.Id) == user
+}
+
 // GetUserById Returns a user by their id
 func GetUserById(id int) *User {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+	userMutex.RLock()
+	defer userMutex.RUnlock()
 
 	return userIdToUser[id]
 }
 
 // GetUserByUsername Returns a user by their username
 func GetUserByUsername(username string) *User {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+	userMutex.RLock()
+	defer userMutex.RUnlock()
 
 	return usernameToUser[strings.ToLower(username)]
 }
 
 // GetUserByConnection Returns a user by their connection to the server
 func GetUserByConnection(conn net.Conn) *User {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+	userMutex.RLock()
+	defer userMutex.RUnlock()
 
 	return connToUser[conn]
 }
 
 // GetOnlineUserCount Returns the number of online users
 func GetOnlineUserCount() int {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+	userMutex.RLock()
+	defer userMutex.RUnlock()
 
 	return len(userIdToUser)
 }
 
-// GetOnlineUserIds Returns a slice of user ids that are online
-func GetOnlineUserIds() []int {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+// GetVisibleOnlineUserCount Returns the number of online users, excluding those that are invisible
+func GetVisibleOnlineUserCount() int {
+	return len(GetVisibleOnlineUsers())
+}
 
+// GetOnlineUserIds Returns a slice of user ids that are online, excluding those that are invisible
+func GetOnlineUserIds() []int {
 	ids := make([]int, 0)
 
-	for _, user := range userIdToUser {
+	for _, user := range GetVisibleOnlineUsers() {
 		ids = append(ids, user.Info.Id)
 	}
 
@@ -121,8 +185,8 @@ func GetOnlineUserIds() []int {
 
 // GetOnlineUsers Returns a slice of users
 func GetOnlineUsers() []*User {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+	userMutex.RLock()
+	defer userMutex.RUnlock()
 
 	users := make([]*User, 0)
 
@@ -133,20 +197,54 @@ func GetOnlineUsers() []*User {
 	return users
 }
 
-// GetSerializedOnlineUsers Returns a list of all online users serialized
-func GetSerializedOnlineUsers() []*objects.PacketUser {
-	userMutex.Lock()
-	defer userMutex.Unlock()
+// GetVisibleOnlineUsers Returns a slice of users, excluding those that are invisible
+func GetVisibleOnlineUsers() []*User {
+	users := make([]*User, 0)
+
+	for _, user := range GetOnlineUsers() {
+		if !user.IsInvisible() {
+			users = append(users, user)
+		}
+	}
 
+	return users
+}
+
+// GetSerializedOnlineUsers Returns a list of all online users serialized, excluding those that are invisible
+func GetSerializedOnlineUsers() []*objects.PacketUser {
 	users := make([]*objects.PacketUser, 0)
 
-	for _, user := range userIdToUser {
+	for _, user := range GetVisibleOnlineUsers() {
 		users = append(users, user.SerializeForPacket())
 	}
 
 	return users
 }
 
+// SetUserInvisible Hides or shows a user to everyone else. Invisible users appear offline to other users
+// and are left out of the online count and presence, but are otherwise able to play as usual.
+func SetUserInvisible(user *User, invisible bool) error {
+	user.Mutex.Lock()
+	user.isInvisible = invisible
+	user.Mutex.Unlock()
+
+	if invisible {
+		_ = BroadcastToAllExcept(packets.NewServerUserDisconnected(user.Info.Id, 0), user)
+
+		if err := removeUserClientStatusFromRedis(user); err != nil {
+			return err
+		}
+	} else {
+		_ = BroadcastToAllExcept(packets.NewServerUserConnected(user.SerializeForPacket()), user)
+
+		if err := addUserClientStatusToRedis(user); err != nil {
+			return err
+		}
+	}
+
+	return UpdateRedisOnlineUserCount()
+}
+
 // AddSpectatorAddedHandler Adds a handler to run when someone spectates a user
 func AddSpectatorAddedHandler(f func(user *User, spectator *User)) {
 	userMutex.Lock()
@@ -163,24 +261,58 @@ func AddSpectatorLeftHandler(f func(user *User, spectator *User)) {
 	spectatorLeftHandlers = append(spectatorLeftHandlers, f)
 }
 
+// AddDisconnectHandler Adds a handler to run when a user disconnects, before their session is removed
+func AddDisconnectHandler(f func(user *User)) {
+	userMutex.Lock()
+	defer userMutex.Unlock()
+
+	disconnectHandlers = append(disconnectHandlers, f)
+}
+
 // Adds a user to the maps that can be used to look them up
 func addUserToMaps(user *User) {
 	userMutex.Lock()
 	defer userMutex.Unlock()
 
-	userIdToUser[user.Info.Id] = user
-	usernameToUser[strings.ToLower(user.Info.Username)] = user
+	userIdToSessions[user.Info.Id] = append(userIdToSessions[user.Info.Id], user)
 	connToUser[user.Conn] = user
+
+	// Additional sessions don't take over from the primary one
+	if _, ok := userIdToUser[user.Info.Id]; !ok {
+		userIdToUser[user.Info.Id] = user
+		usernameToUser[strings.ToLower(user.Info.Username)] = user
+	}
 }
 
-// Removes a user from the maps that are used to look them up
-func removeUserFromMaps(user *User) {
+// Removes a user from the maps that are used to look them up. Returns the user's primary session afterwards,
+// which is nil if they have no sessions left.
+func removeUserFromMaps(user *User) *User {
 	userMutex.Lock()
 	defer userMutex.Unlock()
 
-	delete(userIdToUser, user.Info.Id)
-	delete(usernameToUser, strings.ToLower(user.Info.Username))
-	delete(connToUser, user.Conn)
+	if connToUser[user.Conn] == user {
+		delete(connToUser, user.Conn)
+	}
+
+	remaining := make([]*User, 0, len(userIdToSessions[user.Info.Id]))
+
+	for _, session := range userIdToSessions[user.Info.Id] {
+		if session != user {
+			remaining = append(remaining, session)
+		}
+	}
+
+	if len(remaining) == 0 {
+		delete(userIdToSessions, user.Info.Id)
+		delete(userIdToUser, user.Info.Id)
+		delete(usernameToUser, strings.ToLower(user.Info.Username))
+		return nil
+	}
+
+	userIdToSessions[user.Info.Id] = remaining
+	userIdToUser[user.Info.Id] = remaining[0]
+	usernameToUser[strings.ToLower(user.Info.Username)] = remaining[0]
+	return remaining[0]
 }
 
 // Runs handlers that are used for spectator
@@ -0,0 +1,58 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/db"
+	"net"
+	"testing"
+)
+
+func TestMultipleSessionsPerUser(t *testing.T) {
+	desktop, _ := net.Pipe()
+	mobile, _ := net.Pipe()
+
+	info := &db.User{Id: 1000, SteamId: "1000", Username: "Multi Device"}
+	first := NewUser(desktop, info)
+	second := NewUser(mobile, info)
+
+	addUserToMaps(first)
+	addUserToMaps(second)
+
+	if GetUserById(info.Id) != first || !IsPrimarySession(first) || IsPrimarySession(second) {
+		t.Fatal("Expected the oldest session to be the primary one")
+	}
+
+	if len(GetUserSessions(info.Id)) != 2 {
+		t.Fatal("Expected the user to have 2 sessions")
+	}
+
+	if primary := removeUserFromMaps(first); primary != second || GetUserById(info.Id) != second {
+		t.Fatal("Expected the remaining session to become the primary one")
+	}
+
+	if GetUserByConnection(desktop) != nil {
+		t.Fatal("Expected the removed session's connection to be forgotten")
+	}
+
+	if primary := removeUserFromMaps(second); primary != nil || GetUserById(info.Id) != nil || GetUserByUsername(info.Username) != nil {
+		t.Fatal("Expected the user to be offline after removing every session")
+	}
+}
+
+func TestBroadcastToFriendsOnlyReachesFriends(t *testing.T) {
+	friend := NewUser(nil, &db.User{Id: 1002, SteamId: "1002", Username: "Friend"})
+	stranger := NewUser(nil, &db.User{Id: 1003, SteamId: "1003", Username: "Stranger"})
+
+	friend.SetFriendIds([]int{1004})
+	stranger.SetFriendIds([]int{})
+
+	addUserToMaps(friend)
+	addUserToMaps(stranger)
+	defer removeUserFromMaps(friend)
+	defer removeUserFromMaps(stranger)
+
+	recipients := getFriendsOf(1004)
+
+	if len(recipients) != 1 || recipients[0] != friend {
+		t.Fatalf("Expected only the user with 1004 on their friends list to receive presence, got %v", len(recipients))
+	}
+}
@@ -0,0 +1,93 @@
+package sessions
+
+import (
+	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/db"
+	"example.com/Quaver/Z/packets"
+	"log"
+	"time"
+)
+
+// The amount of times a failed stats query is retried in the background before giving up
+const maxStatsRetries = 5
+
+// LoadStats Fetches the user's stats for every mode. Modes that fail to load are logged and left out,
+// then retried in the background, so a single flaky query doesn't stop the user from logging in.
+func (u *User) LoadStats() {
+	failed := u.fetchStats(getStatsModes())
+
+	if len(failed) > 0 {
+		go u.retryMissingStats(failed)
+	}
+}
+
+// Fetches the stats for the given modes concurrently and merges them into the user's stats.
+// Returns the modes that failed.
+func (u *User) fetchStats(modes []common.Mode) []common.Mode {
+	fetched, errs := u.queryStats(modes)
+	failed := make([]common.Mode, 0)
+
+	for _, mode := range modes {
+		if err, ok := errs[mode]; ok {
+			log.Printf("[%v #%v] Failed to fetch stats for mode %v - %v\n", u.Info.Username, u.Info.Id, mode, err)
+			failed = append(failed, mode)
+		}
+	}
+
+	u.mergeStats(fetched)
+
+	u.Mutex.Lock()
+	u.statsRefreshTimestamp = time.Now().UnixMilli()
+	u.Mutex.Unlock()
+
+	return failed
+}
+
+// Swaps in a new map of stats with the fetched modes replaced, leaving the other modes as they were
+func (u *User) mergeStats(fetched map[common.Mode]*db.UserStats) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	stats := make(map[common.Mode]*db.UserStats, len(u.stats)+len(fetched))
+
+	for mode, modeStats := range u.stats {
+		stats[mode] = modeStats
+	}
+
+	for mode, modeStats := range fetched {
+		stats[mode] = modeStats
+	}
+
+	u.stats = stats
+}
+
+// Retries fetching the stats for modes that failed to load, and sends them to the user once they do
+func (u *User) retryMissingStats(modes []common.Mode) {
+	for attempt := 1; attempt <= maxStatsRetries && len(modes) > 0; attempt++ {
+		time.Sleep(time.Duration(attempt) * 10 * time.Second)
+
+		if u.IsDisconnecting() {
+			return
+		}
+
+		failed := u.fetchStats(modes)
+		stats := u.GetStats()
+		packetStats := map[common.Mode]*db.PacketUserStats{}
+
+		for _, mode := range modes {
+			if modeStats, ok := stats[mode]; ok {
+				packetStats[mode] = modeStats.SerializeForPacket()
+			}
+		}
+
+		if len(packetStats) > 0 {
+			SendPacketToUser(packets.NewServerUserStats(map[int]map[common.Mode]*db.PacketUserStats{u.Info.Id: packetStats}), u)
+		}
+
+		modes = failed
+	}
+
+	if len(modes) > 0 {
+		log.Printf("[%v #%v] Gave up fetching stats for modes %v\n", u.Info.Username, u.Info.Id, modes)
+	}
+}
@@ -1,18 +1,25 @@
 package sessions
 
 import (
+	"database/sql"
 	"example.com/Quaver/Z/common"
+	"example.com/Quaver/Z/config"
 	"example.com/Quaver/Z/db"
 	"example.com/Quaver/Z/objects"
 	"example.com/Quaver/Z/packets"
 	"example.com/Quaver/Z/utils"
 	"fmt"
 	"log"
+	"math"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// The user groups that are able to see moderation details of other users
+const staffUserGroups = common.UserGroupAdmin | common.UserGroupModerator | common.UserGroupDeveloper | common.UserGroupSwan
+
 type User struct {
 	// The connection for the user
 	Conn net.Conn
@@ -31,6 +38,24 @@ type User struct {
 	// Player statistics from the database
 	stats map[common.Mode]*db.UserStats
 
+	// The last time the user's stats were fetched from the database
+	statsRefreshTimestamp int64
+
+	// If the user's stats are currently being fetched from the database
+	isRefreshingStats bool
+
+	// The time the user's session was created
+	connectedTimestamp int64
+
+	// The last time the user's latest activity was updated in the database
+	lastActivityTimestamp int64
+
+	// The last time the user sent a packet other than a pong
+	lastPacketTimestamp int64
+
+	// If the user is shown as away due to inactivity
+	isIdle bool
+
 	// The last time the user was pinged
 	lastPingTimestamp int64
 
@@ -61,22 +86,97 @@ type User struct {
 	// People who the user is currently watching
 	spectating []*User
 
+	// The last time the user switched who they are spectating
+	lastSpectateSwitchTimestamp int64
+
 	// The replay frames for the user's current play session
 	frames []*packets.ClientSpectatorReplayFrames
+
+	// The remote ip address the user connected from
+	ipAddress string
+
+	// The user agent the client advertised during the handshake
+	userAgent string
+
+	// The amount of consecutive packet writes to the user that have failed
+	sendFailureCount int
+
+	// Multiplayer invites the user has sent that haven't been accepted or expired yet
+	outstandingInvites []*outstandingInvite
+
+	// If the user's session is in the process of being removed
+	isDisconnecting bool
+
+	// If the user is hidden from other users and appears offline
+	isInvisible bool
+
+	// If the login handshake has completed and the session is fully established
+	isAuthenticated bool
+
+	// The ids of the users on the user's friends list
+	friendIds []int
+
+	// If the user's friends list has been loaded from the database
+	friendsLoaded bool
+
+	// The ids of the users the user has blocked
+	blockedIds []int
+
+	// The frame rate the client last reported. Zero if it hasn't reported one.
+	reportedFps float64
+
+	// Optional features the client negotiated during login
+	capabilities []string
+
+	// The amount of packets the user is currently able to send before being rate limited
+	packetTokens float64
+
+	// The last time the user's packet tokens were refilled
+	packetTokensRefilled int64
+
+	// If the user has already been told that their packet budget is running low
+	isPacketBudgetLow bool
+
+	// If the user has been muted and hasn't been told that the mute expired yet
+	isMuteExpiryPending bool
+
+	// Makes sure the session is only torn down once
+	disconnectOnce sync.Once
+
+	// Packets waiting to be written to the connection by the user's writer goroutine
+	outbound chan outboundFrames
+
+	// Closed to stop the user's writer goroutine
+	outboundDone chan struct{}
+
+	// Closed by the user's writer goroutine once it has written what was left in the queue and exited
+	outboundDrained chan struct{}
+
+	// Mutex for the outbound channels. Kept separate, as packets are often sent while the user is locked.
+	outboundMutex sync.Mutex
+}
+
+type outstandingInvite struct {
+	gameId    int
+	userId    int
+	timestamp int64
 }
 
 // NewUser Creates a new user session struct object
 func NewUser(conn net.Conn, user *db.User) *User {
 	return &User{
-		Conn:                conn,
-		ConnMutex:           &sync.Mutex{},
-		token:               utils.GenerateRandomString(64),
-		Info:                user,
-		Mutex:               &sync.Mutex{},
-		stats:               map[common.Mode]*db.UserStats{},
-		lastPingTimestamp:   time.Now().UnixMilli(),
-		lastPongTimestamp:   time.Now().UnixMilli(),
-		lastWsPongTimestamp: time.Now().UnixMilli(),
+		Conn:                  conn,
+		ConnMutex:             &sync.Mutex{},
+		token:                 utils.GenerateRandomString(64),
+		Info:                  user,
+		Mutex:                 &sync.Mutex{},
+		stats:                 map[common.Mode]*db.UserStats{},
+		connectedTimestamp:    time.Now().UnixMilli(),
+		lastActivityTimestamp: time.Now().UnixMilli(),
+		lastPacketTimestamp:   time.Now().UnixMilli(),
+		lastPingTimestamp:     time.Now().UnixMilli(),
+		lastPongTimestamp:     time.Now().UnixMilli(),
+		lastWsPongTimestamp:   time.Now().UnixMilli(),
 		status: &objects.ClientStatus{
 			Status:    0,
 			MapId:     -1,
@@ -85,9 +185,10 @@ func NewUser(conn net.Conn, user *db.User) *User {
 			Content:   "",
 			Modifiers: 0,
 		},
-		spectators: []*User{},
-		spectating: []*User{},
-		frames:     []*packets.ClientSpectatorReplayFrames{},
+		spectators:          []*User{},
+		spectating:          []*User{},
+		frames:              []*packets.ClientSpectatorReplayFrames{},
+		isMuteExpiryPending: user.MuteEndTime > time.Now().UnixMilli(),
 	}
 }
 
@@ -96,12 +197,23 @@ func (u *User) GetToken() string {
 	return u.token
 }
 
-// GetStats Retrieves the stats for the user
+// GetStats Retrieves a copy of the stats for the user, so they can be read without holding the lock
 func (u *User) GetStats() map[common.Mode]*db.UserStats {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
-	return u.stats
+	stats := make(map[common.Mode]*db.UserStats, len(u.stats))
+
+	for mode, modeStats := range u.stats {
+		if modeStats == nil {
+			continue
+		}
+
+		copied := *modeStats
+		stats[mode] = &copied
+	}
+
+	return stats
 }
 
 func (u *User) GetStatsSlice() []*db.PacketUserStats {
@@ -114,25 +226,149 @@ func (u *User) GetStatsSlice() []*db.PacketUserStats {
 	return statSlice
 }
 
-// SetStats Updates the statistics for the user
+// SetStats Updates the statistics for the user. Each mode is fetched concurrently into a new map that is only
+// swapped in once every mode succeeded, so readers never see a partially updated map.
 func (u *User) SetStats() error {
-	u.Mutex.Lock()
-	defer u.Mutex.Unlock()
+	modes := getStatsModes()
+	stats, errs := u.queryStats(modes)
 
-	for i := 1; i < int(common.ModeEnumMaxValue); i++ {
-		mode := common.Mode(i)
-		stats, err := db.GetUserStats(u.Info.Id, u.Info.Country, mode)
-
-		if err != nil {
+	for _, mode := range modes {
+		if err, ok := errs[mode]; ok {
 			return err
 		}
+	}
+
+	u.replaceStats(stats)
+	return nil
+}
+
+// Fetches the stats for each of the given modes concurrently.
+// Returns the stats that were fetched, and the error for each mode that failed.
+func (u *User) queryStats(modes []common.Mode) (map[common.Mode]*db.UserStats, map[common.Mode]error) {
+	stats := map[common.Mode]*db.UserStats{}
+	errs := map[common.Mode]error{}
+	mutex := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+
+	for _, mode := range modes {
+		wg.Add(1)
+
+		go func(mode common.Mode) {
+			defer wg.Done()
 
-		u.stats[mode] = stats
+			modeStats, err := db.GetUserStats(u.Info.Id, u.Info.Country, mode)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				errs[mode] = err
+				return
+			}
+
+			stats[mode] = modeStats
+		}(mode)
+	}
+
+	wg.Wait()
+	return stats, errs
+}
+
+// Returns every mode that users have stats for
+func getStatsModes() []common.Mode {
+	modes := make([]common.Mode, 0, common.ModeEnumMaxValue-1)
+
+	for i := 1; i < int(common.ModeEnumMaxValue); i++ {
+		modes = append(modes, common.Mode(i))
 	}
 
+	return modes
+}
+
+// SetStatsForMode Updates the statistics for a single mode, such as after a score was submitted in it
+func (u *User) SetStatsForMode(mode common.Mode) error {
+	if mode < 1 || mode >= common.ModeEnumMaxValue {
+		return fmt.Errorf("invalid mode: %v", mode)
+	}
+
+	modeStats, err := db.GetUserStats(u.Info.Id, u.Info.Country, mode)
+
+	if err != nil {
+		return err
+	}
+
+	u.mergeStats(map[common.Mode]*db.UserStats{mode: modeStats})
 	return nil
 }
 
+// Swaps in a freshly fetched set of stats
+func (u *User) replaceStats(stats map[common.Mode]*db.UserStats) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.stats = stats
+	u.statsRefreshTimestamp = time.Now().UnixMilli()
+}
+
+// GetStatsRefreshTimestamp Retrieves the last time the user's stats were fetched from the database
+func (u *User) GetStatsRefreshTimestamp() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.statsRefreshTimestamp
+}
+
+// RefreshStats Refreshes the user's stats if they are older than maxAge.
+// Refreshes are coalesced, so if one is already in progress, this returns without querying the database again.
+func (u *User) RefreshStats(maxAge time.Duration) error {
+	u.Mutex.Lock()
+
+	if u.isRefreshingStats || time.Now().UnixMilli()-u.statsRefreshTimestamp < maxAge.Milliseconds() {
+		u.Mutex.Unlock()
+		return nil
+	}
+
+	u.isRefreshingStats = true
+	u.Mutex.Unlock()
+
+	defer func() {
+		u.Mutex.Lock()
+		u.isRefreshingStats = false
+		u.Mutex.Unlock()
+	}()
+
+	return u.SetStats()
+}
+
+// GetConnectedTimestamp Retrieves the time the user's session was created
+func (u *User) GetConnectedTimestamp() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.connectedTimestamp
+}
+
+// GetLastActivityTimestamp Retrieves the last time the user's latest activity was updated in the database
+func (u *User) GetLastActivityTimestamp() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.lastActivityTimestamp
+}
+
+// UpdateLatestActivity Updates the user's last seen time in the database. Failures are only logged.
+func (u *User) UpdateLatestActivity() {
+	u.Mutex.Lock()
+	u.lastActivityTimestamp = time.Now().UnixMilli()
+	u.Mutex.Unlock()
+
+	err := db.UpdateUserLatestActivity(u.Info.Id)
+
+	if err != nil {
+		log.Printf("[%v #%v] Failed to update latest activity - %v\n", u.Info.Username, u.Info.Id, err)
+	}
+}
+
 // GetLastPingTimestamp Retrieves the last ping timestamp
 func (u *User) GetLastPingTimestamp() int64 {
 	u.Mutex.Lock()
@@ -166,6 +402,9 @@ func (u *User) SetLastPongTimestamp() {
 }
 
 func (u *User) GetLastWsPongTimestamp() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
 	return u.lastWsPongTimestamp
 }
 
@@ -192,17 +431,30 @@ func (u *User) SetLastDetectedProcesses(processes []string) {
 	u.lastDetectedProcesses = processes
 }
 
-// GetClientStatus Gets the current user client status
+// GetClientStatus Gets the current user client status. Idle users are shown as away.
 func (u *User) GetClientStatus() *objects.ClientStatus {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
+	if u.isIdle {
+		status := *u.status
+		status.Status = objects.ClientStatusAway
+		return &status
+	}
+
 	return u.status
 }
 
-// SetClientStatus Sets the current user client status
+// SetClientStatus Sets the current user client status. Ignored if the session is disconnecting,
+// so a late packet doesn't re-cache the status of a user who is already gone.
 func (u *User) SetClientStatus(status *objects.ClientStatus) {
 	u.Mutex.Lock()
+
+	if u.isDisconnecting {
+		u.Mutex.Unlock()
+		return
+	}
+
 	u.status = status
 	u.Mutex.Unlock()
 
@@ -213,6 +465,153 @@ func (u *User) SetClientStatus(status *objects.ClientStatus) {
 	}
 }
 
+// IsDisconnecting Returns if the user's session is in the process of being removed
+func (u *User) IsDisconnecting() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.isDisconnecting
+}
+
+// SetDisconnecting Marks the user's session as being removed
+func (u *User) SetDisconnecting() {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.isDisconnecting = true
+}
+
+// HasCapability Returns if the client negotiated an optional feature during login
+func (u *User) HasCapability(capability string) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return utils.Includes(u.capabilities, capability)
+}
+
+// SetCapabilities Sets the optional features the client negotiated during login
+func (u *User) SetCapabilities(capabilities []string) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.capabilities = capabilities
+}
+
+// GetReportedFps Returns the frame rate the client last reported. Zero if it hasn't reported one.
+func (u *User) GetReportedFps() float64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.reportedFps
+}
+
+// SetReportedFps Sets the frame rate the client last reported
+func (u *User) SetReportedFps(fps float64) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.reportedFps = math.Max(fps, 0)
+}
+
+// GetFriends Returns the ids of the users on the user's friends list. The list is fetched from the database
+// the first time it is needed and cached on the session afterwards.
+func (u *User) GetFriends() []int {
+	u.Mutex.Lock()
+	loaded := u.friendsLoaded
+	u.Mutex.Unlock()
+
+	if !loaded {
+		friends, err := db.GetUserFriendsList(u.Info.Id)
+
+		if err != nil {
+			log.Printf("[%v #%v] Failed to fetch friends list - %v\n", u.Info.Username, u.Info.Id, err)
+			return []int{}
+		}
+
+		u.SetFriendIds(friends)
+	}
+
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return append([]int{}, u.friendIds...)
+}
+
+// SetFriendIds Sets the cached friends list of the user
+func (u *User) SetFriendIds(ids []int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.friendIds = ids
+	u.friendsLoaded = true
+}
+
+// HasFriend Returns if a user is on the cached friends list of the user
+func (u *User) HasFriend(id int) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return utils.Includes(u.friendIds, id)
+}
+
+// AddFriendId Adds a user to the cached friends list of the user
+func (u *User) AddFriendId(id int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	if !utils.Includes(u.friendIds, id) {
+		u.friendIds = append(u.friendIds, id)
+	}
+}
+
+// RemoveFriendId Removes a user from the cached friends list of the user
+func (u *User) RemoveFriendId(id int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.friendIds = utils.Filter(u.friendIds, func(x int) bool { return x != id })
+}
+
+// SetBlockedIds Sets the cached list of users the user has blocked
+func (u *User) SetBlockedIds(ids []int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.blockedIds = ids
+}
+
+// IsBlocking Returns if the user has blocked another user
+func (u *User) IsBlocking(id int) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return utils.Includes(u.blockedIds, id)
+}
+
+// IsAuthenticated Returns if the login handshake has completed and the session is fully established
+func (u *User) IsAuthenticated() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.isAuthenticated
+}
+
+// SetAuthenticated Marks the login handshake as completed
+func (u *User) SetAuthenticated() {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.isAuthenticated = true
+}
+
+// IsInvisible Returns if the user is hidden from other users and appears offline
+func (u *User) IsInvisible() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.isInvisible
+}
+
 // GetSpammedMessagesCount Gets the amount of messages the user has spammed
 func (u *User) GetSpammedMessagesCount() int {
 	u.Mutex.Lock()
@@ -252,6 +651,85 @@ func (u *User) SetSpammedChatLastTimeCleared(time int64) {
 	u.spammedChatLastTimeCleared = time
 }
 
+// GetIpAddress Gets the ip address the user connected from
+func (u *User) GetIpAddress() string {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.ipAddress
+}
+
+// GetUserAgent Gets the user agent the client advertised when connecting
+func (u *User) GetUserAgent() string {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.userAgent
+}
+
+// SetConnectionInfo Sets the ip address and user agent the user connected with
+func (u *User) SetConnectionInfo(ipAddress string, userAgent string) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.ipAddress = ipAddress
+	u.userAgent = userAgent
+}
+
+// GetSendFailureCount Gets the amount of consecutive failed packet writes to the user
+func (u *User) GetSendFailureCount() int {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return u.sendFailureCount
+}
+
+// IncrementSendFailureCount Increments the amount of consecutive failed packet writes by 1
+func (u *User) IncrementSendFailureCount() {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.sendFailureCount++
+}
+
+// ResetSendFailureCount Resets the amount of consecutive failed packet writes after a successful write
+func (u *User) ResetSendFailureCount() {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.sendFailureCount = 0
+}
+
+// AddOutstandingInvite Records a sent multiplayer invite. Returns false if the user already has the maximum
+// amount of outstanding invites within the window.
+func (u *User) AddOutstandingInvite(gameId int, userId int, max int, window time.Duration) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	now := time.Now().UnixMilli()
+
+	u.outstandingInvites = utils.Filter(u.outstandingInvites, func(x *outstandingInvite) bool {
+		return now-x.timestamp < window.Milliseconds() && !(x.gameId == gameId && x.userId == userId)
+	})
+
+	if len(u.outstandingInvites) >= max {
+		return false
+	}
+
+	u.outstandingInvites = append(u.outstandingInvites, &outstandingInvite{gameId: gameId, userId: userId, timestamp: now})
+	return true
+}
+
+// RemoveOutstandingInvite Frees up the capacity of an invite once it has been accepted
+func (u *User) RemoveOutstandingInvite(gameId int, userId int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	u.outstandingInvites = utils.Filter(u.outstandingInvites, func(x *outstandingInvite) bool {
+		return !(x.gameId == gameId && x.userId == userId)
+	})
+}
+
 // GetMultiplayerGameId Gets the id of the multiplayer game the user is currently inside of (if any)
 func (u *User) GetMultiplayerGameId() int {
 	u.Mutex.Lock()
@@ -272,17 +750,54 @@ func (u *User) IsMuted() bool {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
+	return u.isMuted()
+}
+
+// CheckMuteExpired Returns true once after a user's mute has expired, so they can be told they're able to chat again
+func (u *User) CheckMuteExpired() bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	if !u.isMuteExpiryPending || u.isMuted() {
+		return false
+	}
+
+	u.isMuteExpiryPending = false
+	return true
+}
+
+// Returns if the user is muted. The user must be locked by the caller.
+func (u *User) isMuted() bool {
 	return u.Info.MuteEndTime > time.Now().UnixMilli()
 }
 
+// GetMuteTimeRemaining Returns the amount of milliseconds left on the user's mute, or zero if they aren't muted
+func (u *User) GetMuteTimeRemaining() int64 {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	remaining := u.Info.MuteEndTime - time.Now().UnixMilli()
+
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
 // MuteUser Mutes a user for a specified duration
-func (u *User) MuteUser(duration time.Duration) error {
+func (u *User) MuteUser(duration time.Duration, reason string, byId int) error {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
 	endTime := time.Now().UnixMilli() + duration.Milliseconds()
 
-	err := db.MuteUser(u.Info.Id, endTime)
+	// Unmuting clears who muted the user and why
+	if duration <= 0 {
+		reason, byId = "", 0
+	}
+
+	err := db.MuteUser(u.Info.Id, endTime, reason, byId)
 
 	if err != nil {
 		log.Printf("Failed to update user mute time: %v\n", err)
@@ -290,9 +805,29 @@ func (u *User) MuteUser(duration time.Duration) error {
 	}
 
 	u.Info.MuteEndTime = endTime
+	u.Info.MuteReason = sql.NullString{String: reason, Valid: reason != ""}
+	u.Info.MutedById = sql.NullInt64{Int64: int64(byId), Valid: byId != 0}
+
+	if u.isMuted() {
+		u.isMuteExpiryPending = true
+	}
+
 	return nil
 }
 
+// MuteInfo Returns when the user's mute ends, why they were muted and who muted them.
+// Everything is zeroed if the user isn't muted.
+func (u *User) MuteInfo() (endTime int64, reason string, byId int) {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	if !u.isMuted() {
+		return 0, "", 0
+	}
+
+	return u.Info.MuteEndTime, u.Info.MuteReason.String, int(u.Info.MutedById.Int64)
+}
+
 // GetSpectators Returns the people who are currently spectating this user
 func (u *User) GetSpectators() []*User {
 	u.Mutex.Lock()
@@ -355,6 +890,22 @@ func (u *User) RemoveSpectator(spectator *User) {
 	runSpectatorHandlers(spectatorLeftHandlers, u, spectator)
 }
 
+// TrySpectateSwitch Returns if enough time has passed since the user last switched spectator targets,
+// and if so, records the switch.
+func (u *User) TrySpectateSwitch(cooldown time.Duration) bool {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	now := time.Now().UnixMilli()
+
+	if now-u.lastSpectateSwitchTimestamp < cooldown.Milliseconds() {
+		return false
+	}
+
+	u.lastSpectateSwitchTimestamp = now
+	return true
+}
+
 // StopSpectatingAll Stops spectating every user that they are currently spectating
 func (u *User) StopSpectatingAll() {
 	for _, user := range u.GetSpectating() {
@@ -363,9 +914,18 @@ func (u *User) StopSpectatingAll() {
 }
 
 func (u *User) ClearReplayFrames() {
+	releaseReplayFrames(len(u.frames))
 	u.frames = []*packets.ClientSpectatorReplayFrames{}
 }
 
+// GetReplayFrameCount Returns the amount of replay frames buffered for the user's current play session
+func (u *User) GetReplayFrameCount() int {
+	u.Mutex.Lock()
+	defer u.Mutex.Unlock()
+
+	return len(u.frames)
+}
+
 // HandleNewSpectatorFrames Handles incoming replay frames
 func (u *User) HandleNewSpectatorFrames(packet *packets.ClientSpectatorReplayFrames) {
 	u.Mutex.Lock()
@@ -378,7 +938,9 @@ func (u *User) HandleNewSpectatorFrames(packet *packets.ClientSpectatorReplayFra
 	case packets.SpectatorFrameNewSong, packets.SpectatorFrameSelectingSong:
 		u.ClearReplayFrames()
 	default:
-		u.frames = append(u.frames, packet)
+		if reserveReplayFrame() {
+			u.frames = append(u.frames, packet)
+		}
 	}
 
 	u.Mutex.Unlock()
@@ -404,7 +966,7 @@ func (u *User) SerializeForPacket() *objects.PacketUser {
 	u.Mutex.Lock()
 	defer u.Mutex.Unlock()
 
-	return &objects.PacketUser{
+	packetUser := &objects.PacketUser{
 		Id:          u.Info.Id,
 		SteamId:     u.Info.SteamId,
 		Username:    u.Info.Username,
@@ -412,14 +974,38 @@ func (u *User) SerializeForPacket() *objects.PacketUser {
 		MuteEndTime: u.Info.MuteEndTime,
 		Country:     u.Info.Country,
 	}
+
+	if config.Instance != nil && config.Instance.Server.SerializeUserGroupNames {
+		packetUser.GroupNames = common.GetUserGroupNames(u.Info.UserGroups)
+	}
+
+	return packetUser
+}
+
+// SerializeForViewer Serializes the user for a packet that is sent to a specific user. Staff also receive
+// why the user is muted and who muted them.
+func (u *User) SerializeForViewer(viewer *User) *objects.PacketUser {
+	packetUser := u.SerializeForPacket()
+
+	if viewer == nil || !viewer.Info.UserGroups.HasAny(staffUserGroups) {
+		return packetUser
+	}
+
+	_, packetUser.MuteReason, packetUser.MutedById = u.MuteInfo()
+	return packetUser
 }
 
 // Returns the Redis key for the user's session
 func (u *User) getRedisSessionKey() string {
-	return fmt.Sprintf("quaver:server:session:%v", u.token)
+	return db.RedisKey("session", u.token)
+}
+
+// Returns the Redis key for the set of the user's active session tokens
+func (u *User) getRedisUserSessionsKey() string {
+	return db.RedisKey("user_sessions", strconv.Itoa(u.Info.Id))
 }
 
-// Returns the Redis key for the user's client
+// Returns the Redis key for the user's client status
 func (u *User) getRedisClientStatusKey() string {
-	return fmt.Sprintf("quaver:server:user_status:%v", u.Info.Id)
+	return db.RedisKey("user_status", strconv.Itoa(u.Info.Id))
 }
@@ -33,3 +33,98 @@ func TestPopulateUserStats(t *testing.T) {
 
 	db.CloseSQLConnection()
 }
+
+func TestLoadStats(t *testing.T) {
+	_ = config.Load("../config.json")
+
+	if config.Instance == nil {
+		return
+	}
+
+	db.InitializeSQL()
+	db.InitializeRedis()
+
+	user := NewUser(nil, &db.User{Id: 1})
+
+	if failed := user.fetchStats(getStatsModes()); len(failed) != 0 {
+		t.Fatalf("expected every mode to load, failed: %v", failed)
+	}
+
+	if stats := user.GetStats(); len(stats) != int(common.ModeEnumMaxValue)-1 {
+		t.Fatalf("expected (%v) mode stats. only fetched %v", int(common.ModeEnumMaxValue)-1, len(stats))
+	}
+
+	db.CloseSQLConnection()
+}
+
+func TestGetStatsReturnsCopy(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1, SteamId: "1", Username: "User #1"})
+	user.replaceStats(map[common.Mode]*db.UserStats{common.ModeKeys4: {PlayCount: 1}})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 1000; i++ {
+			user.replaceStats(map[common.Mode]*db.UserStats{common.ModeKeys4: {PlayCount: i}})
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		for _, stats := range user.GetStats() {
+			stats.PlayCount++
+		}
+	}
+
+	<-done
+
+	stats := user.GetStats()
+	stats[common.ModeKeys4].PlayCount = -1
+
+	if user.GetStats()[common.ModeKeys4].PlayCount == -1 {
+		t.Fatal("Expected changes to the returned stats to not affect the session")
+	}
+}
+
+func TestParseLastSeen(t *testing.T) {
+	lastSeen, err := parseLastSeen("1700000000123")
+
+	if err != nil || lastSeen.UnixMilli() != 1700000000123 {
+		t.Fatalf("Expected the stored timestamp to be parsed, got %v (%v)", lastSeen, err)
+	}
+
+	if _, err := parseLastSeen("yesterday"); err == nil {
+		t.Fatal("Expected an invalid timestamp to fail to parse")
+	}
+}
+
+func TestIsBlocking(t *testing.T) {
+	user := NewUser(nil, &db.User{Id: 1005, SteamId: "1005", Username: "Blocker"})
+	user.SetBlockedIds([]int{1006})
+
+	if !user.IsBlocking(1006) || user.IsBlocking(1007) {
+		t.Fatal("Expected only the blocked user to be blocked")
+	}
+}
+
+func TestUserRedisKeysUsePrefix(t *testing.T) {
+	defer func(prefix string) { db.KeyPrefix = prefix }(db.KeyPrefix)
+	db.KeyPrefix = "staging:"
+
+	user := NewUser(nil, &db.User{Id: 1008, SteamId: "1008", Username: "Prefixed"})
+	user.token = "token"
+
+	keys := map[string]string{
+		user.getRedisSessionKey():      "staging:session:token",
+		user.getRedisUserSessionsKey(): "staging:user_sessions:1008",
+		user.getRedisClientStatusKey(): "staging:user_status:1008",
+		getRedisLastSeenKey(1008):      "staging:last_seen:1008",
+	}
+
+	for key, expected := range keys {
+		if key != expected {
+			t.Fatalf("expected %v, got %v", expected, key)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // CloseConnection Closes a connection
@@ -35,10 +39,11 @@ func CloseConnectionDelayed(conn net.Conn) {
 
 func ReadData(rw io.ReadWriter, s ws.State, want ws.OpCode) ([]byte, ws.OpCode, error) {
 	controlHandler := wsutil.ControlFrameHandler(rw, s)
+	// Compressed payloads aren't valid UTF-8 until they're inflated, so text is checked after reading instead
 	rd := wsutil.Reader{
 		Source:          rw,
 		State:           s,
-		CheckUTF8:       true,
+		CheckUTF8:       false,
 		SkipHeaderCheck: false,
 		OnIntermediate:  controlHandler,
 	}
@@ -65,6 +70,92 @@ func ReadData(rw io.ReadWriter, s ws.State, want ws.OpCode) ([]byte, ws.OpCode,
 
 		bts, err := ioutil.ReadAll(&rd)
 
+		// The first rsv bit marks messages compressed with permessage-deflate. The inflated size is capped,
+		// so a small frame can't expand into enough data to exhaust the server's memory.
+		if err == nil && hdr.Rsv1() {
+			bts, err = InflateMessage(bts, MaxInflatedMessageSize)
+		}
+
+		if err == nil && hdr.OpCode == ws.OpText && !utf8.Valid(bts) {
+			err = wsutil.ErrInvalidUTF8
+		}
+
 		return bts, hdr.OpCode, err
 	}
 }
+
+// TrustedProxies The ip addresses or CIDR ranges of the reverse proxies that are trusted to set X-Forwarded-For
+var TrustedProxies []string
+
+// GetRequestIpAddress Returns the ip address of the client that made a request. X-Forwarded-For is only honoured
+// when the request comes from a trusted proxy, in which case the right-most hop that isn't a trusted proxy is used.
+// Anything to the left of it was written by the client, so it can't be trusted.
+func GetRequestIpAddress(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+
+		if hop == "" {
+			continue
+		}
+
+		ip = hop
+
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// Returns if an ip address is one of the trusted proxies
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(parsed) {
+				return true
+			}
+
+			continue
+		}
+
+		if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactIpAddress Hides the host portion of an ip address so it can be shown in non-privileged views
+func RedactIpAddress(ip string) string {
+	parsed := net.ParseIP(ip)
+
+	if parsed == nil {
+		return "redacted"
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%v.%v.x.x", v4[0], v4[1])
+	}
+
+	return parsed.Mask(net.CIDRMask(32, 128)).String() + "/32"
+}
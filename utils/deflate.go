@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+)
+
+// PermessageDeflate The name of the websocket compression extension
+const PermessageDeflate = "permessage-deflate"
+
+// ErrMessageTooLarge Returned when a compressed message inflates to more than the maximum message size
+var ErrMessageTooLarge = errors.New("inflated message is larger than the maximum message size")
+
+// MaxInflatedMessageSize The maximum size in bytes that a compressed message from a client can inflate to
+var MaxInflatedMessageSize = 1024 * 1024
+
+// The bytes that end every flushed deflate block, which permessage-deflate leaves out of messages
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// DeflateMessage Compresses a websocket message payload for permessage-deflate without context takeover
+func DeflateMessage(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), deflateTail), nil
+}
+
+// InflateMessage Decompresses a websocket message payload that was compressed with permessage-deflate.
+// ErrMessageTooLarge is returned if the message inflates to more than maxSize bytes. Unlimited if maxSize is zero.
+func InflateMessage(data []byte, maxSize int) ([]byte, error) {
+	r := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail)))
+	defer r.Close()
+
+	var src io.Reader = r
+
+	// One byte more than the limit is read, so a message that is exactly at the limit is still allowed
+	if maxSize > 0 {
+		src = io.LimitReader(r, int64(maxSize)+1)
+	}
+
+	out, err := io.ReadAll(src)
+
+	// The stream has no final block, so running out of input after the tail is expected.
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	if err == nil && maxSize > 0 && len(out) > maxSize {
+		return nil, ErrMessageTooLarge
+	}
+
+	return out, err
+}
@@ -3,9 +3,9 @@ package utils
 import (
 	"fmt"
 	"github.com/TwiN/go-away"
-	"math"
 	"math/rand"
 	"time"
+	"unicode/utf8"
 )
 
 var randomSeeded = false
@@ -22,9 +22,27 @@ func GenerateRandomString(length int) string {
 	return fmt.Sprintf("%x", b)[:length]
 }
 
-// TruncateString Truncates a string to a given max length
+// TruncateString Truncates a string to a given max length in runes, so multibyte characters are never split
 func TruncateString(str string, maxLength int) string {
-	return str[:int(math.Min(float64(len(str)), float64(maxLength)))]
+	if maxLength <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(str) <= maxLength {
+		return str
+	}
+
+	runes := 0
+
+	for i := range str {
+		if runes == maxLength {
+			return str[:i]
+		}
+
+		runes++
+	}
+
+	return str
 }
 
 // BoolToEnabledString Converts a bool into an "enabled" or "disabled" string